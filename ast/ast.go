@@ -3,6 +3,8 @@ package ast
 import (
 	"bytes"
 	"fmt"
+	"math/big"
+	"strings"
 
 	"github.com/sayandipdutta/monkey/token"
 )
@@ -10,6 +12,7 @@ import (
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position
 }
 
 type Statement interface {
@@ -44,6 +47,13 @@ func (prog *Program) TokenLiteral() string {
 	return ""
 }
 
+func (prog *Program) Pos() token.Position {
+	if len(prog.Statements) > 0 {
+		return prog.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
 // LetStatment
 type LetStatment struct {
 	Name  *Identifier
@@ -53,6 +63,7 @@ type LetStatment struct {
 
 func (stmt *LetStatment) statementNode()       {}
 func (stmt *LetStatment) TokenLiteral() string { return stmt.Token.Literal }
+func (stmt *LetStatment) Pos() token.Position  { return stmt.Token.Pos }
 func (stmt *LetStatment) String() string {
 	var out bytes.Buffer
 
@@ -66,6 +77,36 @@ func (stmt *LetStatment) String() string {
 	return out.String()
 }
 
+// AssignStatement covers plain (`x = 5`) and compound (`x += 1`) assignment.
+type AssignStatement struct {
+	Token    token.Token // the assignment operator token
+	Target   Expression
+	Operator string
+	Value    Expression
+}
+
+func (stmt *AssignStatement) statementNode()       {}
+func (stmt *AssignStatement) TokenLiteral() string { return stmt.Token.Literal }
+func (stmt *AssignStatement) Pos() token.Position {
+	if stmt.Target != nil {
+		return stmt.Target.Pos()
+	}
+	return stmt.Token.Pos
+}
+func (stmt *AssignStatement) String() string {
+	var out bytes.Buffer
+
+	if stmt.Target != nil {
+		out.WriteString(stmt.Target.String())
+	}
+	out.WriteString(" " + stmt.Operator + " ")
+	if stmt.Value != nil {
+		out.WriteString(stmt.Value.String())
+	}
+	out.WriteString(";")
+	return out.String()
+}
+
 // Identifier
 type Identifier struct {
 	Token token.Token
@@ -74,6 +115,7 @@ type Identifier struct {
 
 func (ident *Identifier) expressionNode()      {}
 func (ident *Identifier) TokenLiteral() string { return ident.Token.Literal }
+func (ident *Identifier) Pos() token.Position  { return ident.Token.Pos }
 func (ident *Identifier) String() string       { return ident.Value }
 
 // IntegerLiteral
@@ -83,8 +125,62 @@ type IntegerLiteral struct {
 }
 
 func (ident *IntegerLiteral) expressionNode()      {}
-func (ident *IntegerLiteral) String() string       { return fmt.Sprintf("%d", ident.Value) }
 func (ident *IntegerLiteral) TokenLiteral() string { return ident.Token.Literal }
+func (ident *IntegerLiteral) Pos() token.Position  { return ident.Token.Pos }
+
+// String keeps the original spelling, such as 0xFF or 1_000, when there is one.
+func (ident *IntegerLiteral) String() string {
+	if ident.Token.Literal != "" {
+		return ident.Token.Literal
+	}
+	return fmt.Sprintf("%d", ident.Value)
+}
+
+// BigIntegerLiteral is an integer literal too large for an int64. The
+// parser only produces it in parser.BigIntegers mode.
+type BigIntegerLiteral struct {
+	Token token.Token
+	Value *big.Int
+}
+
+func (bi *BigIntegerLiteral) expressionNode()      {}
+func (bi *BigIntegerLiteral) TokenLiteral() string { return bi.Token.Literal }
+func (bi *BigIntegerLiteral) Pos() token.Position  { return bi.Token.Pos }
+func (bi *BigIntegerLiteral) String() string       { return bi.Token.Literal }
+
+// FloatLiteral
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() token.Position  { return fl.Token.Pos }
+
+// StringLiteral; Value holds the unescaped contents while the token
+// literal keeps the original spelling.
+type StringLiteral struct {
+	Token token.Token
+	Value string
+}
+
+func (str *StringLiteral) expressionNode()      {}
+func (str *StringLiteral) TokenLiteral() string { return str.Token.Literal }
+func (str *StringLiteral) Pos() token.Position  { return str.Token.Pos }
+func (str *StringLiteral) String() string       { return str.Token.Literal }
+
+// Boolean
+type Boolean struct {
+	Token token.Token
+	Value bool
+}
+
+func (b *Boolean) expressionNode()      {}
+func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
+func (b *Boolean) Pos() token.Position  { return b.Token.Pos }
+func (b *Boolean) String() string       { return b.Token.Literal }
 
 // ReturnStatement
 type ReturnStatement struct {
@@ -94,12 +190,13 @@ type ReturnStatement struct {
 
 func (stmt *ReturnStatement) statementNode()       {}
 func (stmt *ReturnStatement) TokenLiteral() string { return stmt.Token.Literal }
+func (stmt *ReturnStatement) Pos() token.Position  { return stmt.Token.Pos }
 func (stmt *ReturnStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(stmt.TokenLiteral())
 	if stmt.Value != nil {
-		out.WriteString(stmt.Value.String())
+		out.WriteString(" " + stmt.Value.String())
 	}
 	out.WriteString(";")
 	return out.String()
@@ -113,6 +210,7 @@ type ExpressionStatement struct {
 
 func (stmt *ExpressionStatement) statementNode()       {}
 func (stmt *ExpressionStatement) TokenLiteral() string { return stmt.Token.Literal }
+func (stmt *ExpressionStatement) Pos() token.Position  { return stmt.Token.Pos }
 func (stmt *ExpressionStatement) String() string {
 	var out bytes.Buffer
 
@@ -130,6 +228,7 @@ type IntegerExpression struct {
 
 func (stmt *IntegerExpression) expressionNode()      {}
 func (stmt *IntegerExpression) TokenLiteral() string { return stmt.Token.Literal }
+func (stmt *IntegerExpression) Pos() token.Position  { return stmt.Token.Pos }
 func (stmt *IntegerExpression) String() string       { return stmt.TokenLiteral() }
 
 // PrefixExpression
@@ -141,6 +240,7 @@ type PrefixExpression struct {
 
 func (expr *PrefixExpression) expressionNode()      {}
 func (expr *PrefixExpression) TokenLiteral() string { return expr.Token.Literal }
+func (expr *PrefixExpression) Pos() token.Position  { return expr.Token.Pos }
 func (expr *PrefixExpression) String() string {
 	return fmt.Sprintf("(%s%s)", expr.Operator, expr.Right.String())
 }
@@ -154,6 +254,157 @@ type InfixExpression struct {
 
 func (expr *InfixExpression) expressionNode()      {}
 func (expr *InfixExpression) TokenLiteral() string { return expr.Token.Literal }
+func (expr *InfixExpression) Pos() token.Position  { return expr.Token.Pos }
 func (expr *InfixExpression) String() string {
 	return fmt.Sprintf("(%s %s %s)", expr.Left.String(), expr.Operator, expr.Right.String())
 }
+
+// LogicalExpression is `&&` or `||`. It is kept apart from InfixExpression
+// because its right operand is only evaluated when needed.
+type LogicalExpression struct {
+	Left     Expression
+	Right    Expression
+	Token    token.Token
+	Operator string
+}
+
+func (expr *LogicalExpression) expressionNode()      {}
+func (expr *LogicalExpression) TokenLiteral() string { return expr.Token.Literal }
+func (expr *LogicalExpression) Pos() token.Position  { return expr.Token.Pos }
+func (expr *LogicalExpression) String() string {
+	return fmt.Sprintf("(%s %s %s)", expr.Left.String(), expr.Operator, expr.Right.String())
+}
+
+// BlockStatement
+type BlockStatement struct {
+	Token      token.Token
+	Statements []Statement
+}
+
+func (block *BlockStatement) statementNode()       {}
+func (block *BlockStatement) TokenLiteral() string { return block.Token.Literal }
+func (block *BlockStatement) Pos() token.Position  { return block.Token.Pos }
+func (block *BlockStatement) String() string {
+	var out bytes.Buffer
+
+	for _, s := range block.Statements {
+		out.WriteString(s.String())
+	}
+	return out.String()
+}
+
+// IfExpression; an `else if` chain is represented as an Alternative block
+// holding a single nested IfExpression.
+type IfExpression struct {
+	Token       token.Token
+	Condition   Expression
+	Consequence *BlockStatement
+	Alternative *BlockStatement
+}
+
+func (expr *IfExpression) expressionNode()      {}
+func (expr *IfExpression) TokenLiteral() string { return expr.Token.Literal }
+func (expr *IfExpression) Pos() token.Position  { return expr.Token.Pos }
+func (expr *IfExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("if")
+	out.WriteString(expr.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(expr.Consequence.String())
+
+	if expr.Alternative != nil {
+		out.WriteString(" else ")
+		out.WriteString(expr.Alternative.String())
+	}
+	return out.String()
+}
+
+// FunctionLiteral
+type FunctionLiteral struct {
+	Token      token.Token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (fn *FunctionLiteral) expressionNode()      {}
+func (fn *FunctionLiteral) TokenLiteral() string { return fn.Token.Literal }
+func (fn *FunctionLiteral) Pos() token.Position  { return fn.Token.Pos }
+func (fn *FunctionLiteral) String() string {
+	params := make([]string, 0, len(fn.Parameters))
+	for _, p := range fn.Parameters {
+		params = append(params, p.String())
+	}
+	return fmt.Sprintf("%s(%s) %s", fn.TokenLiteral(), strings.Join(params, ", "), fn.Body.String())
+}
+
+// CallExpression
+type CallExpression struct {
+	Token     token.Token // the '(' token
+	Function  Expression  // Identifier or FunctionLiteral
+	Arguments []Expression
+}
+
+func (expr *CallExpression) expressionNode()      {}
+func (expr *CallExpression) TokenLiteral() string { return expr.Token.Literal }
+func (expr *CallExpression) Pos() token.Position  { return expr.Token.Pos }
+func (expr *CallExpression) String() string {
+	args := make([]string, 0, len(expr.Arguments))
+	for _, a := range expr.Arguments {
+		args = append(args, a.String())
+	}
+	return fmt.Sprintf("%s(%s)", expr.Function.String(), strings.Join(args, ", "))
+}
+
+// ArrayLiteral
+type ArrayLiteral struct {
+	Token    token.Token // the '[' token
+	Elements []Expression
+}
+
+func (array *ArrayLiteral) expressionNode()      {}
+func (array *ArrayLiteral) TokenLiteral() string { return array.Token.Literal }
+func (array *ArrayLiteral) Pos() token.Position  { return array.Token.Pos }
+func (array *ArrayLiteral) String() string {
+	elements := make([]string, 0, len(array.Elements))
+	for _, el := range array.Elements {
+		elements = append(elements, el.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// IndexExpression
+type IndexExpression struct {
+	Token token.Token // the '[' token
+	Left  Expression
+	Index Expression
+}
+
+func (expr *IndexExpression) expressionNode()      {}
+func (expr *IndexExpression) TokenLiteral() string { return expr.Token.Literal }
+func (expr *IndexExpression) Pos() token.Position  { return expr.Token.Pos }
+func (expr *IndexExpression) String() string {
+	return fmt.Sprintf("(%s[%s])", expr.Left.String(), expr.Index.String())
+}
+
+// HashLiteral keeps its pairs in source order.
+type HashLiteral struct {
+	Token token.Token // the '{' token
+	Pairs []HashPair
+}
+
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+func (hash *HashLiteral) expressionNode()      {}
+func (hash *HashLiteral) TokenLiteral() string { return hash.Token.Literal }
+func (hash *HashLiteral) Pos() token.Position  { return hash.Token.Pos }
+func (hash *HashLiteral) String() string {
+	pairs := make([]string, 0, len(hash.Pairs))
+	for _, pair := range hash.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
@@ -0,0 +1,702 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/sayandipdutta/monkey/ast"
+	"github.com/sayandipdutta/monkey/object"
+)
+
+var (
+	NULL  = &object.Null{}
+	TRUE  = &object.Boolean{Value: true}
+	FALSE = &object.Boolean{Value: false}
+)
+
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	// Statements
+	case *ast.Program:
+		return evalProgram(node, env)
+	case *ast.ExpressionStatement:
+		return Eval(node.Expression, env)
+	case *ast.BlockStatement:
+		return evalBlockStatement(node, env)
+	case *ast.ReturnStatement:
+		if node.Value == nil {
+			return &object.ReturnValue{Value: NULL}
+		}
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+	case *ast.LetStatment:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		env.Set(node.Name.Value, val)
+		return NULL
+	case *ast.AssignStatement:
+		return evalAssignStatement(node, env)
+
+	// Expressions
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: node.Value}
+	case *ast.BigIntegerLiteral:
+		return &object.BigInteger{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(node.Value)
+	case *ast.PrefixExpression:
+		right := Eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return evalPrefixExpression(node.Operator, right)
+	case *ast.InfixExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		right := Eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return evalInfixExpression(node.Operator, left, right)
+	case *ast.LogicalExpression:
+		return evalLogicalExpression(node, env)
+	case *ast.IfExpression:
+		return evalIfExpression(node, env)
+	case *ast.Identifier:
+		return evalIdentifier(node, env)
+	case *ast.FunctionLiteral:
+		return &object.Function{Parameters: node.Parameters, Body: node.Body, Env: env}
+	case *ast.CallExpression:
+		function := Eval(node.Function, env)
+		if isError(function) {
+			return function
+		}
+		args := evalExpressions(node.Arguments, env)
+		if len(args) == 1 && isError(args[0]) {
+			return args[0]
+		}
+		return applyFunction(function, args)
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+	case *ast.IndexExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := Eval(node.Index, env)
+		if isError(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
+	}
+
+	return newError("cannot evaluate %T", node)
+}
+
+func evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	var result object.Object = NULL
+
+	for _, stmt := range program.Statements {
+		result = Eval(stmt, env)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return result.Value
+		case *object.Error:
+			return result
+		}
+	}
+	return result
+}
+
+// evalBlockStatement stops at the first return value or error but, unlike
+// evalProgram, does not unwrap it, so that it keeps unwinding through any
+// enclosing blocks.
+func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object = NULL
+
+	for _, stmt := range block.Statements {
+		result = Eval(stmt, env)
+
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+	}
+	return result
+}
+
+func evalPrefixExpression(operator string, right object.Object) object.Object {
+	switch operator {
+	case "!":
+		return nativeBoolToBooleanObject(!isTruthy(right))
+	case "-":
+		switch right := right.(type) {
+		case *object.Integer:
+			if right.Value == math.MinInt64 {
+				return &object.BigInteger{Value: new(big.Int).Neg(big.NewInt(right.Value))}
+			}
+			return &object.Integer{Value: -right.Value}
+		case *object.BigInteger:
+			return normalizeBigInteger(new(big.Int).Neg(right.Value))
+		case *object.Float:
+			return &object.Float{Value: -right.Value}
+		default:
+			return newError("unknown operator: -%s", right.Type())
+		}
+	case "~":
+		switch right := right.(type) {
+		case *object.Integer:
+			return &object.Integer{Value: ^right.Value}
+		case *object.BigInteger:
+			return normalizeBigInteger(new(big.Int).Not(right.Value))
+		default:
+			return newError("unknown operator: ~%s", right.Type())
+		}
+	default:
+		return newError("unknown operator: %s%s", operator, right.Type())
+	}
+}
+
+func evalInfixExpression(operator string, left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalIntegerInfixExpression(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntegerInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right)
+	case left.Type() != right.Type():
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	case operator == "==":
+		return nativeBoolToBooleanObject(left == right)
+	case operator == "!=":
+		return nativeBoolToBooleanObject(left != right)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalIntegerInfixExpression handles arithmetic on two int64 Integers. A
+// result that does not fit in an int64 is computed as a BigInteger instead
+// of wrapping around.
+func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Integer).Value
+	rightVal := right.(*object.Integer).Value
+
+	switch operator {
+	case "+":
+		if sum := leftVal + rightVal; (sum > leftVal) == (rightVal > 0) {
+			return &object.Integer{Value: sum}
+		}
+		return evalBigIntegerInfixExpression(operator, left, right)
+	case "-":
+		if diff := leftVal - rightVal; (diff < leftVal) == (rightVal > 0) {
+			return &object.Integer{Value: diff}
+		}
+		return evalBigIntegerInfixExpression(operator, left, right)
+	case "*":
+		if leftVal == 0 || rightVal == 0 {
+			return &object.Integer{Value: 0}
+		}
+		if product := leftVal * rightVal; product/rightVal == leftVal && !(rightVal == -1 && leftVal == math.MinInt64) {
+			return &object.Integer{Value: product}
+		}
+		return evalBigIntegerInfixExpression(operator, left, right)
+	case "**", "<<":
+		// these overflow for most operands, so leave the checks to big.Int
+		return evalBigIntegerInfixExpression(operator, left, right)
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero: %d / %d", leftVal, rightVal)
+		}
+		if rightVal == -1 && leftVal == math.MinInt64 {
+			return evalBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("division by zero: %d %% %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: leftVal % rightVal}
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "|":
+		return &object.Integer{Value: leftVal | rightVal}
+	case "^":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case ">>":
+		if rightVal < 0 {
+			return newError("negative shift count: %d %s %d", leftVal, operator, rightVal)
+		}
+		return &object.Integer{Value: leftVal >> rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalFloatInfixExpression handles arithmetic where at least one operand is
+// a float; an integer operand is promoted to float first.
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
+	case "/":
+		return &object.Float{Value: leftVal / rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// maxIntegerBits bounds the size of a BigInteger that `**` or `<<` may
+// produce, so that a single expression cannot exhaust memory.
+const maxIntegerBits = 1 << 22
+
+// evalBigIntegerInfixExpression handles integer arithmetic where at least
+// one operand is a BigInteger.
+func evalBigIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toBigInt(left)
+	rightVal := toBigInt(right)
+
+	switch operator {
+	case "+":
+		return normalizeBigInteger(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return normalizeBigInteger(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return normalizeBigInteger(new(big.Int).Mul(leftVal, rightVal))
+	case "**":
+		if rightVal.Sign() < 0 {
+			return evalFloatInfixExpression(operator, left, right)
+		}
+		if leftVal.CmpAbs(big.NewInt(1)) > 0 &&
+			(!rightVal.IsUint64() || rightVal.Uint64() > maxIntegerBits/uint64(leftVal.BitLen())) {
+			return newError("exponent too large: %s ** %s", leftVal, rightVal)
+		}
+		return normalizeBigInteger(new(big.Int).Exp(leftVal, rightVal, nil))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero: %s / %s", leftVal, rightVal)
+		}
+		return normalizeBigInteger(new(big.Int).Quo(leftVal, rightVal))
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero: %s %% %s", leftVal, rightVal)
+		}
+		return normalizeBigInteger(new(big.Int).Rem(leftVal, rightVal))
+	case "&":
+		return normalizeBigInteger(new(big.Int).And(leftVal, rightVal))
+	case "|":
+		return normalizeBigInteger(new(big.Int).Or(leftVal, rightVal))
+	case "^":
+		return normalizeBigInteger(new(big.Int).Xor(leftVal, rightVal))
+	case "<<", ">>":
+		if rightVal.Sign() < 0 {
+			return newError("negative shift count: %s %s %s", leftVal, operator, rightVal)
+		}
+		if operator == ">>" {
+			// shifting right by the bit length or more gives 0 or -1
+			shift := uint64(leftVal.BitLen())
+			if rightVal.IsUint64() && rightVal.Uint64() < shift {
+				shift = rightVal.Uint64()
+			}
+			return normalizeBigInteger(new(big.Int).Rsh(leftVal, uint(shift)))
+		}
+		if leftVal.Sign() == 0 {
+			return &object.Integer{Value: 0}
+		}
+		if !rightVal.IsUint64() || rightVal.Uint64() > maxIntegerBits ||
+			leftVal.BitLen()+int(rightVal.Uint64()) > maxIntegerBits {
+			return newError("shift count too large: %s %s %s", leftVal, operator, rightVal)
+		}
+		return normalizeBigInteger(new(big.Int).Lsh(leftVal, uint(rightVal.Uint64())))
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// normalizeBigInteger returns an Integer if value fits in one, so that
+// arithmetic only stays arbitrary-precision while it has to.
+func normalizeBigInteger(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInteger{Value: value}
+}
+
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIG_INTEGER_OBJ
+}
+
+func isNumber(obj object.Object) bool {
+	return isInteger(obj) || obj.Type() == object.FLOAT_OBJ
+}
+
+func toBigInt(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	case *object.BigInteger:
+		return obj.Value
+	default:
+		return new(big.Int)
+	}
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInteger:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return f
+	case *object.Float:
+		return obj.Value
+	default:
+		return 0
+	}
+}
+
+func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
+
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalLogicalExpression short-circuits: the right operand is only evaluated
+// if the left one does not decide the result. Like `if`, it goes by
+// truthiness, and the result is the operand that decided it, so that
+// `name || "anonymous"` yields a string.
+func evalLogicalExpression(expr *ast.LogicalExpression, env *object.Environment) object.Object {
+	left := Eval(expr.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	switch expr.Operator {
+	case "&&":
+		if !isTruthy(left) {
+			return left
+		}
+	case "||":
+		if isTruthy(left) {
+			return left
+		}
+	default:
+		return newError("unknown operator: %s %s", left.Type(), expr.Operator)
+	}
+
+	return Eval(expr.Right, env)
+}
+
+func evalIfExpression(expr *ast.IfExpression, env *object.Environment) object.Object {
+	condition := Eval(expr.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return Eval(expr.Consequence, env)
+	} else if expr.Alternative != nil {
+		return Eval(expr.Alternative, env)
+	}
+	return NULL
+}
+
+func evalIdentifier(ident *ast.Identifier, env *object.Environment) object.Object {
+	val, ok := env.Get(ident.Value)
+	if !ok {
+		return newError("identifier not found: %s", ident.Value)
+	}
+	return val
+}
+
+// evalAssignStatement rebinds an existing name or replaces an array
+// element. A compound operator such as `+=` applies the corresponding infix
+// operator to the current value first.
+func evalAssignStatement(stmt *ast.AssignStatement, env *object.Environment) object.Object {
+	val := Eval(stmt.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	switch target := stmt.Target.(type) {
+	case *ast.Identifier:
+		if stmt.Operator != "=" {
+			val = evalCompoundAssignment(stmt.Operator, evalIdentifier(target, env), val)
+			if isError(val) {
+				return val
+			}
+		}
+		if !env.Assign(target.Value, val) {
+			return newError("identifier not found: %s", target.Value)
+		}
+		return NULL
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return index
+		}
+		if stmt.Operator != "=" {
+			val = evalCompoundAssignment(stmt.Operator, evalIndexExpression(left, index), val)
+			if isError(val) {
+				return val
+			}
+		}
+		return evalIndexAssignment(left, index, val)
+	default:
+		return newError("cannot assign to %s", stmt.Target.String())
+	}
+}
+
+// evalCompoundAssignment computes the new value for `current op= val`.
+func evalCompoundAssignment(operator string, current, val object.Object) object.Object {
+	if isError(current) {
+		return current
+	}
+	return evalInfixExpression(strings.TrimSuffix(operator, "="), current, val)
+}
+
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		array := left.(*object.Array)
+		i, err := arrayIndex(array, index.(*object.Integer).Value)
+		if err != nil {
+			return err
+		}
+		return array.Elements[i]
+	case left.Type() == object.ARRAY_OBJ:
+		return newError("array index must be INTEGER, got %s", index.Type())
+	case left.Type() == object.HASH_OBJ:
+		key, err := hashKey(index)
+		if err != nil {
+			return err
+		}
+		pair, ok := left.(*object.Hash).Pairs[key]
+		if !ok {
+			return NULL
+		}
+		return pair.Value
+	default:
+		return newError("index operator not supported: %s", left.Type())
+	}
+}
+
+func evalIndexAssignment(left, index, val object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		array := left.(*object.Array)
+		i, err := arrayIndex(array, index.(*object.Integer).Value)
+		if err != nil {
+			return err
+		}
+		array.Elements[i] = val
+		return NULL
+	case left.Type() == object.ARRAY_OBJ:
+		return newError("array index must be INTEGER, got %s", index.Type())
+	case left.Type() == object.HASH_OBJ:
+		key, err := hashKey(index)
+		if err != nil {
+			return err
+		}
+		left.(*object.Hash).Pairs[key] = object.HashPair{Key: index, Value: val}
+		return NULL
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	pairs := make(map[object.HashKey]object.HashPair, len(node.Pairs))
+
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if isError(key) {
+			return key
+		}
+		hashed, err := hashKey(key)
+		if err != nil {
+			return err
+		}
+
+		value := Eval(pair.Value, env)
+		if isError(value) {
+			return value
+		}
+		pairs[hashed] = object.HashPair{Key: key, Value: value}
+	}
+	return &object.Hash{Pairs: pairs}
+}
+
+// hashKey returns the key for obj, or an error if obj cannot be a hash key.
+func hashKey(obj object.Object) (object.HashKey, *object.Error) {
+	hashable, ok := obj.(object.Hashable)
+	if !ok {
+		return object.HashKey{}, newError("unusable as hash key: %s", obj.Type())
+	}
+	return hashable.HashKey(), nil
+}
+
+// arrayIndex resolves index against array, counting negative indices from
+// the end, and checks that it is in bounds.
+func arrayIndex(array *object.Array, index int64) (int, *object.Error) {
+	length := int64(len(array.Elements))
+	i := index
+	if i < 0 {
+		i += length
+	}
+	if i < 0 || i >= length {
+		return 0, newError("index out of range: %d with length %d", index, length)
+	}
+	return int(i), nil
+}
+
+// evalExpressions evaluates exprs left to right. If one of them fails the
+// result is a single-element slice holding the error.
+func evalExpressions(exprs []ast.Expression, env *object.Environment) []object.Object {
+	result := make([]object.Object, 0, len(exprs))
+
+	for _, expr := range exprs {
+		evaluated := Eval(expr, env)
+		if isError(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+	return result
+}
+
+func applyFunction(fn object.Object, args []object.Object) object.Object {
+	function, ok := fn.(*object.Function)
+	if !ok {
+		return newError("not a function: %s", fn.Type())
+	}
+
+	if len(args) != len(function.Parameters) {
+		return newError("wrong number of arguments: want=%d, got=%d", len(function.Parameters), len(args))
+	}
+
+	env := object.NewEnclosedEnvironment(function.Env)
+	for i, param := range function.Parameters {
+		env.Set(param.Value, args[i])
+	}
+
+	evaluated := Eval(function.Body, env)
+	return unwrapReturnValue(evaluated)
+}
+
+func unwrapReturnValue(obj object.Object) object.Object {
+	if returnValue, ok := obj.(*object.ReturnValue); ok {
+		return returnValue.Value
+	}
+	return obj
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return TRUE
+	}
+	return FALSE
+}
+
+// isTruthy reports whether obj counts as true in a condition: everything
+// except null and false.
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case NULL, FALSE:
+		return false
+	default:
+		return true
+	}
+}
+
+func newError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
+
+func isError(obj object.Object) bool {
+	return obj != nil && obj.Type() == object.ERROR_OBJ
+}
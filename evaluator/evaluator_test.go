@@ -0,0 +1,711 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/sayandipdutta/monkey/evaluator"
+	"github.com/sayandipdutta/monkey/lexer"
+	"github.com/sayandipdutta/monkey/object"
+	"github.com/sayandipdutta/monkey/parser"
+)
+
+func testEval(t *testing.T, input string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if len(p.Diagnostics) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+	env := object.NewEnvironment()
+	return evaluator.Eval(program, env)
+}
+
+func TestEvalIntegerExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"5", 5},
+		{"10", 10},
+		{"-5", -5},
+		{"-10", -10},
+		{"5 + 5 + 5 + 5 - 10", 10},
+		{"2 * 2 * 2 * 2 * 2", 32},
+		{"-50 + 100 + -50", 0},
+		{"5 * 2 + 10", 20},
+		{"5 + 2 * 10", 25},
+		{"20 + 2 * -10", 0},
+		{"50 / 2 * 2 + 10", 60},
+		{"2 * (5 + 10)", 30},
+		{"3 * 3 * 3 + 10", 37},
+		{"3 * (3 * 3) + 10", 37},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"1 << 4", 16},
+		{"256 >> 2 + 2", 66},
+		{"256 >> (2 + 2)", 16},
+		{"-9223372036854775808", -9223372036854775808},
+		{"-9223372036854775808 + 1", -9223372036854775807},
+		{"9223372036854775807 + 1 - 1", 9223372036854775807},
+		{"-9223372036854775807 - 1", -9223372036854775808},
+		{"-9223372036854775807 - 2 + 2", -9223372036854775807},
+		{"4611686018427387904 * 2 / 2", 4611686018427387904},
+		{"-1 * -9223372036854775808 - 1", 9223372036854775807},
+		{"1 << 63 >> 1", 4611686018427387904},
+		{"3 ** 40 / 3 ** 39", 3},
+		{"1 << 2 | 1", 5},
+		{"2 * 1 << 3", 16},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"2 + 7 % 4", 5},
+		{"12 & 10", 8},
+		{"12 | 10", 14},
+		{"12 ^ 10", 6},
+		{"~0", -1},
+		{"~5 & 0xFF", 250},
+		{"1 | 2 & 3", 3},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"(2 ** 3) ** 2", 64},
+		{"-2 ** 2", -4},
+		{"(-2) ** 3", -8},
+		{"5 ** 0", 1},
+		{"3 * 2 ** 2", 12},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{"-.5", -0.5},
+		{"1.5 + 1.5", 3},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2},
+		{"1 / 4.0", 0.25},
+		{"2.5e2 - 50", 200},
+		{"let x = 1; x += 0.5; x", 1.5},
+		{"2 ** -2", 0.25},
+		{"2.0 ** 3", 8},
+		{"4 ** 0.5", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		testFloatObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5 + 0.5", "2.0"},
+		{"0.1 + 0.2", "0.30000000000000004"},
+		{"1e-9", "1e-09"},
+		{"1.0 / 0", "+Inf"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong Inspect for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestBigIntegerArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775808", "9223372036854775808"},
+		{"-9223372036854775808", "-9223372036854775808"},
+		{"9223372036854775807 * 2 + 1", "18446744073709551615"},
+		{"let x = 9223372036854775808; x - 1 + 1", "9223372036854775808"},
+		{"18446744073709551616 - 1", "18446744073709551615"},
+		{"18446744073709551616 * 18446744073709551616", "340282366920938463463374607431768211456"},
+		{"18446744073709551616 / 4294967296", "4294967296"},
+		{"18446744073709551616 >> 64", "1"},
+		{"18446744073709551617 % 10", "7"},
+		{"18446744073709551616 ** 2", "340282366920938463463374607431768211456"},
+		{"18446744073709551616 ** 0", "1"},
+		{"1 ** 18446744073709551616", "1"},
+		{"2 ** 18446744073709551616", "ERROR: exponent too large: 2 ** 18446744073709551616"},
+		{"99999999999999999999 ** 50000000", "ERROR: exponent too large: 99999999999999999999 ** 50000000"},
+		{"2 ** 4194304", "ERROR: exponent too large: 2 ** 4194304"},
+		{"(2 ** 2000000) >> 1999999", "2"},
+		{"1 << 4194303 >> 4194303", "1"},
+		{"1 << 4194304", "ERROR: shift count too large: 1 << 4194304"},
+		{"18446744073709551616 << 4194300", "ERROR: shift count too large: 18446744073709551616 << 4194300"},
+		{"0 << 18446744073709551616", "0"},
+		{"-18446744073709551616 >> 18446744073709551616", "-1"},
+		{"18446744073709551616 >> 18446744073709551616", "0"},
+		{"18446744073709551616 | 1", "18446744073709551617"},
+		{"18446744073709551617 & 0xFF", "1"},
+		{"~18446744073709551616", "-18446744073709551617"},
+		{"18446744073709551616 > 1", "true"},
+		{"18446744073709551616 == 18446744073709551616", "true"},
+		{"18446744073709551616 + 0.5", "1.8446744073709552e+19"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.NewWithMode(l, parser.BigIntegers)
+		program := p.ParseProgram()
+		if len(p.Diagnostics) != 0 {
+			t.Fatalf("parser errors for %q: %v", tt.input, p.Errors())
+		}
+
+		evaluated := evaluator.Eval(program, object.NewEnvironment())
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestIntegerOverflowPromotes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"4294967296 * 4294967296", "18446744073709551616"},
+		{"-(-9223372036854775808)", "9223372036854775808"},
+		{"-9223372036854775808 / -1", "9223372036854775808"},
+		{"2 ** 64", "18446744073709551616"},
+		{"1 << 64", "18446744073709551616"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestEvalBooleanExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"false", false},
+		{"1 < 2", true},
+		{"1 > 2", false},
+		{"1 <= 1", true},
+		{"2 >= 3", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"true == true", true},
+		{"true != false", true},
+		{"(1 < 2) == true", true},
+		{"(1 > 2) == true", false},
+		{"1 == 1.0", true},
+		{"0.5 < 1", true},
+		{"2 >= 2.5", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestBangOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"!true", false},
+		{"!false", true},
+		{"!5", false},
+		{"!!true", true},
+		{"!!false", false},
+		{"!!5", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestIfElseExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"if (true) { 10 }", 10},
+		{"if (false) { 10 }", nil},
+		{"if (1) { 10 }", 10},
+		{"if (1 < 2) { 10 }", 10},
+		{"if (1 > 2) { 10 }", nil},
+		{"if (1 > 2) { 10 } else { 20 }", 20},
+		{"if (1 < 2) { 10 } else { 20 }", 10},
+		{"if (1 > 2) { 10 } else if (2 > 1) { 30 } else { 20 }", 30},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if integer, ok := tt.expected.(int); ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestReturnStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"return 10;", 10},
+		{"return 10; 9;", 10},
+		{"return 2 * 5; 9;", 10},
+		{"9; return 2 * 5; 9;", 10},
+		{"if (10 > 1) { if (10 > 1) { return 10; } return 1; }", 10},
+		{"return;", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if integer, ok := tt.expected.(int); ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestErrorHandling(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"5 + true;", "type mismatch: INTEGER + BOOLEAN"},
+		{"5 + true; 5;", "type mismatch: INTEGER + BOOLEAN"},
+		{"-true", "unknown operator: -BOOLEAN"},
+		{"true + false;", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"5; true + false; 5", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"if (10 > 1) { true + false; }", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"if (10 > 1) { if (10 > 1) { return true + false; } return 1; }", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"1 / 0", "division by zero: 1 / 0"},
+		{"1 << -1", "negative shift count: 1 << -1"},
+		{"1.5 << 1", "unknown operator: FLOAT << INTEGER"},
+		{"1.5 + true", "type mismatch: FLOAT + BOOLEAN"},
+		{"1 % 0", "division by zero: 1 % 0"},
+		{"true ** 2", "type mismatch: BOOLEAN ** INTEGER"},
+		{"true & false", "unknown operator: BOOLEAN & BOOLEAN"},
+		{"1 | true", "type mismatch: INTEGER | BOOLEAN"},
+		{"~true", "unknown operator: ~BOOLEAN"},
+		{"1.5 % 1", "unknown operator: FLOAT % INTEGER"},
+		{"foobar", "identifier not found: foobar"},
+		{"x = 1", "identifier not found: x"},
+		{"let x = true; x += 1", "type mismatch: BOOLEAN + INTEGER"},
+		{"5(1)", "not a function: INTEGER"},
+		{"true && foobar", "identifier not found: foobar"},
+		{`"Hello" - "World"`, "unknown operator: STRING - STRING"},
+		{`"a" + 1`, "type mismatch: STRING + INTEGER"},
+		{"fn(x) { x }()", "wrong number of arguments: want=1, got=0"},
+		{"[1, 2, 3][3]", "index out of range: 3 with length 3"},
+		{"[1, 2, 3][-4]", "index out of range: -4 with length 3"},
+		{"[1, 2, 3][true]", "array index must be INTEGER, got BOOLEAN"},
+		{"1[0]", "index operator not supported: INTEGER"},
+		{"let xs = [1]; xs[1] = 2", "index out of range: 1 with length 1"},
+		{`{"name": "Monkey"}[fn(x) { x }];`, "unusable as hash key: FUNCTION"},
+		{`{[1]: 2}`, "unusable as hash key: ARRAY"},
+		{`let h = {}; h[{}] = 1`, "unusable as hash key: HASH"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let a = 5; a;", 5},
+		{"let a = 5 * 5; a;", 25},
+		{"let a = 5; let b = a; b;", 5},
+		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestAssignStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let a = 5; a = 6; a;", 6},
+		{"let a = 5; a += 2; a;", 7},
+		{"let a = 5; a -= 2; a;", 3},
+		{"let a = 5; a *= 2; a;", 10},
+		{"let a = 5; a /= 2; a;", 2},
+		{"let a = 1; let f = fn() { a = 2 }; f(); a;", 2},
+		{"let a = 1; let f = fn(a) { a = 2 }; f(5); a;", 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestFunctionObject(t *testing.T) {
+	input := "fn(x) { x + 2; };"
+
+	evaluated := testEval(t, input)
+	fn, ok := evaluated.(*object.Function)
+	if !ok {
+		t.Fatalf("object is not Function. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if len(fn.Parameters) != 1 {
+		t.Fatalf("function has wrong parameters. got=%v", fn.Parameters)
+	}
+
+	if fn.Parameters[0].String() != "x" {
+		t.Fatalf("parameter is not 'x'. got=%q", fn.Parameters[0])
+	}
+
+	if fn.Body.String() != "(x + 2)" {
+		t.Fatalf("body is not %q. got=%q", "(x + 2)", fn.Body.String())
+	}
+}
+
+func TestFunctionApplication(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let identity = fn(x) { x; }; identity(5);", 5},
+		{"let identity = fn(x) { return x; }; identity(5);", 5},
+		{"let double = fn(x) { x * 2; }; double(5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5, 5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
+		{"fn(x) { x; }(5)", 5},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestClosures(t *testing.T) {
+	input := `
+let adder = fn(x) { fn(y) { x + y } };
+let addTwo = adder(2);
+addTwo(3);`
+
+	testIntegerObject(t, testEval(t, input), 5)
+}
+
+func TestClosureCounter(t *testing.T) {
+	input := `
+let counter = fn() { let n = 0; fn() { n += 1; n } };
+let next = counter();
+next();
+next();
+next();`
+
+	testIntegerObject(t, testEval(t, input), 3)
+}
+
+func TestStringLiteral(t *testing.T) {
+	evaluated := testEval(t, `"Hello\tWorld!"`)
+
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if str.Value != "Hello\tWorld!" {
+		t.Fatalf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestStringConcatenation(t *testing.T) {
+	evaluated := testEval(t, `let greet = fn(name) { "Hello" + " " + name + "!" }; greet("World")`)
+
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if str.Value != "Hello World!" {
+		t.Fatalf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestStringComparison(t *testing.T) {
+	testBooleanObject(t, testEval(t, `"a" == "a"`), true)
+	testBooleanObject(t, testEval(t, `"a" != "a"`), false)
+	testBooleanObject(t, testEval(t, `"a" == "b"`), false)
+}
+
+func TestArrayLiterals(t *testing.T) {
+	evaluated := testEval(t, "[1, 2 * 2, 3 + 3]")
+
+	result, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if len(result.Elements) != 3 {
+		t.Fatalf("array has wrong number of elements. got=%d", len(result.Elements))
+	}
+
+	testIntegerObject(t, result.Elements[0], 1)
+	testIntegerObject(t, result.Elements[1], 4)
+	testIntegerObject(t, result.Elements[2], 6)
+
+	if result.Inspect() != "[1, 4, 6]" {
+		t.Fatalf("wrong Inspect. got=%s", result.Inspect())
+	}
+}
+
+func TestArrayIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"[1, 2, 3][0]", 1},
+		{"[1, 2, 3][1]", 2},
+		{"[1, 2, 3][2]", 3},
+		{"let i = 0; [1][i];", 1},
+		{"[1, 2, 3][1 + 1];", 3},
+		{"let myArray = [1, 2, 3]; myArray[2];", 3},
+		{"let myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];", 6},
+		{"[1, 2, 3][-1]", 3},
+		{"[1, 2, 3][-3]", 1},
+		{"[[1, 2], [3, 4]][1][0]", 3},
+		{"let xs = [1, 2, 3]; xs[0] = 10; xs[0]", 10},
+		{"let xs = [1, 2, 3]; xs[-1] *= 5; xs[2]", 15},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestHashLiterals(t *testing.T) {
+	input := `let two = "two";
+{
+	"one": 10 - 9,
+	two: 1 + 1,
+	"thr" + "ee": 6 / 2,
+	4: 4,
+	true: 5,
+	false: 6
+}`
+
+	evaluated := testEval(t, input)
+	result, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := map[object.HashKey]int64{
+		(&object.String{Value: "one"}).HashKey():   1,
+		(&object.String{Value: "two"}).HashKey():   2,
+		(&object.String{Value: "three"}).HashKey(): 3,
+		(&object.Integer{Value: 4}).HashKey():      4,
+		evaluator.TRUE.HashKey():                   5,
+		evaluator.FALSE.HashKey():                  6,
+	}
+
+	if len(result.Pairs) != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	}
+
+	for expectedKey, expectedValue := range expected {
+		pair, ok := result.Pairs[expectedKey]
+		if !ok {
+			t.Errorf("no pair for given key in Pairs")
+		}
+		testIntegerObject(t, pair.Value, expectedValue)
+	}
+}
+
+func TestStringHashKey(t *testing.T) {
+	hello1 := &object.String{Value: "Hello World"}
+	hello2 := &object.String{Value: "Hello World"}
+	diff := &object.String{Value: "My name is johnny"}
+
+	if hello1.HashKey() != hello2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+	if hello1.HashKey() == diff.HashKey() {
+		t.Errorf("strings with different content have same hash keys")
+	}
+	if (&object.String{Value: ""}).HashKey() == (&object.Integer{Value: 0}).HashKey() {
+		t.Errorf("empty string and 0 have same hash keys")
+	}
+}
+
+func TestHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`let key = "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`{false: 5}[false]`, 5},
+		{`let h = {"a": 1}; h["a"] += 2; h["b"] = 4; h["a"] + h["b"]`, 7},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if integer, ok := tt.expected.(int); ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestHashInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"b": 2, "a": [1], 3: true}`, `{3: true, "a": [1], "b": 2}`},
+		{`{1: 1, "1": 3}`, `{1: 1, "1": 3}`},
+		{`{10: 1, 2: 2, -1: 0, true: 5, false: 4}`, `{false: 4, true: 5, -1: 0, 2: 2, 10: 1}`},
+		{`{"say \"hi\"": 1}`, `{"say \"hi\"": 1}`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong Inspect for %s. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := `let größe = 2; let x1 = größe * 21; x1`
+	testIntegerObject(t, testEval(t, input), 42)
+}
+
+func TestLogicalExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"true && true", true},
+		{"true && false", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"1 < 2 && 2 < 3", true},
+		{"false && undefined", false},
+		{"true || undefined", true},
+		{"1 && 2", 2},
+		{"0 || 5", 0},
+		{"if (false) { 1 } || 7", 7},
+		{"false && 5", false},
+		{"let x = 1; let set = fn() { x = 2; true }; false && set(); x", 1},
+		{"let x = 1; let set = fn() { x = 2; true }; true || set(); x", 1},
+		{"let x = 1; let set = fn() { x = 2; true }; true && set(); x", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestEvalProgramWithParseErrors(t *testing.T) {
+	p := parser.New(lexer.New("let = 1;"))
+	program := p.ParseProgram()
+	if len(p.Diagnostics) == 0 {
+		t.Fatalf("expected diagnostics, got none")
+	}
+
+	// must not panic on the statements that failed to parse
+	evaluator.Eval(program, object.NewEnvironment())
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("object is not Integer. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. expected=%d, got=%d", expected, result.Value)
+		return false
+	}
+	return true
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. expected=%g, got=%g", expected, result.Value)
+		return false
+	}
+	return true
+}
+
+func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
+	result, ok := obj.(*object.Boolean)
+	if !ok {
+		t.Errorf("object is not Boolean. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. expected=%t, got=%t", expected, result.Value)
+		return false
+	}
+	return true
+}
+
+func testNullObject(t *testing.T, obj object.Object) bool {
+	if obj != evaluator.NULL {
+		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package lexer
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// decodeEscape decodes the escape sequence at the start of s, which must
+// begin with a backslash. It returns the decoded rune and the number of
+// bytes the sequence occupies; msg is non-empty if the sequence is invalid.
+func decodeEscape(s string) (r rune, size int, msg string) {
+	if len(s) < 2 {
+		return 0, len(s), "incomplete escape sequence"
+	}
+
+	switch s[1] {
+	case 'n':
+		return '\n', 2, ""
+	case 't':
+		return '\t', 2, ""
+	case '\\':
+		return '\\', 2, ""
+	case '"':
+		return '"', 2, ""
+	case 'u':
+		if len(s) < 3 || s[2] != '{' {
+			return 0, 2, `\u must be followed by {hex digits}`
+		}
+		end := strings.IndexAny(s[3:], "}\"")
+		if end < 0 || s[3+end] != '}' {
+			return 0, 3, `unterminated \u{...} escape`
+		}
+		digits := s[3 : 3+end]
+		size = 3 + end + 1
+		if len(digits) == 0 || len(digits) > 6 {
+			return 0, size, `\u{...} escape must have 1 to 6 hex digits`
+		}
+		value, err := strconv.ParseUint(digits, 16, 32)
+		if err != nil {
+			return 0, size, "invalid hex digits in \\u{" + digits + "}"
+		}
+		if !utf8.ValidRune(rune(value)) {
+			return 0, size, "\\u{" + digits + "} is not a valid Unicode code point"
+		}
+		return rune(value), size, ""
+	default:
+		_, width := utf8.DecodeRuneInString(s[1:])
+		return 0, 1 + width, "unknown escape sequence \\" + s[1:1+width]
+	}
+}
+
+// Unquote returns the value of a STRING token literal, resolving its escape
+// sequences.
+func Unquote(literal string) (string, error) {
+	if len(literal) < 2 || literal[0] != '"' || literal[len(literal)-1] != '"' {
+		return "", errors.New("string literal is not quoted")
+	}
+	s := literal[1 : len(literal)-1]
+
+	var out strings.Builder
+	for len(s) > 0 {
+		if s[0] != '\\' {
+			out.WriteByte(s[0])
+			s = s[1:]
+			continue
+		}
+		r, size, msg := decodeEscape(s)
+		if msg != "" {
+			return "", errors.New(msg)
+		}
+		out.WriteRune(r)
+		s = s[size:]
+	}
+	return out.String(), nil
+}
@@ -1,36 +1,132 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/sayandipdutta/monkey/token"
 )
 
+// Mode is a set of flags that change how the lexer behaves.
+type Mode uint
+
+const (
+	// ScanComments makes the lexer return comments as COMMENT tokens
+	// instead of skipping them.
+	ScanComments Mode = 1 << iota
+)
+
 type Lexer struct {
 	input        string
+	filename     string
+	mode         Mode
 	currPosition int
 	nextPosition int
-	ch           byte
+	line         int
+	column       int
+	ch           rune
+	errors       []Error
+}
+
+// Error describes a malformed token in the range [Start, End). The token
+// itself is still returned, usually as ILLEGAL, so that parsing can go on.
+type Error struct {
+	Start   token.Position
+	End     token.Position
+	Message string
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewFile("", input)
+}
+
+// NewFile returns a Lexer whose token positions carry the given filename.
+func NewFile(filename, input string) *Lexer {
+	return NewFileWithMode(filename, input, 0)
+}
+
+func NewFileWithMode(filename, input string, mode Mode) *Lexer {
+	l := &Lexer{input: input, filename: filename, mode: mode, line: 1}
 	l.readChar()
+	if l.ch == byteOrderMark {
+		l.readChar()
+		l.column = 1
+	}
 	return l
 }
 
+const byteOrderMark = '\uFEFF'
+
+// readChar advances to the next character, decoding the input as UTF-8.
+// Positions keep counting bytes for Offset and characters for Column.
 func (lexer *Lexer) readChar() {
+	if lexer.ch == '\n' {
+		lexer.line += 1
+		lexer.column = 0
+	}
+	width := 0
 	if lexer.nextPosition >= len(lexer.input) {
 		lexer.ch = 0
 	} else {
-		lexer.ch = lexer.input[lexer.nextPosition]
+		lexer.ch, width = utf8.DecodeRuneInString(lexer.input[lexer.nextPosition:])
 	}
 	lexer.currPosition = lexer.nextPosition
-	lexer.nextPosition += 1
+	lexer.nextPosition += width
+	lexer.column += 1
+
+	if lexer.invalidEncoding() {
+		lexer.errorf(lexer.position(), "invalid UTF-8 encoding")
+	}
+}
+
+// invalidEncoding reports whether the current character could not be
+// decoded, as opposed to being a literal U+FFFD.
+func (lexer *Lexer) invalidEncoding() bool {
+	return lexer.ch == utf8.RuneError && lexer.nextPosition-lexer.currPosition == 1
+}
+
+// Errors returns every error found so far, in source order.
+func (lexer *Lexer) Errors() []Error {
+	return lexer.errors
+}
+
+// errorf records an error spanning from start up to and including the
+// current character.
+func (lexer *Lexer) errorf(start token.Position, message string) {
+	end := lexer.position()
+	if lexer.currPosition < len(lexer.input) {
+		end.Offset = lexer.nextPosition
+		end.Column += 1
+	}
+	lexer.errorAt(start, end, message)
+}
+
+func (lexer *Lexer) errorAt(start, end token.Position, message string) {
+	lexer.errors = append(lexer.errors, Error{Start: start, End: end, Message: message})
+}
+
+// position returns the source position of the current character.
+func (lexer *Lexer) position() token.Position {
+	return token.Position{
+		Filename: lexer.filename,
+		Offset:   min(lexer.currPosition, len(lexer.input)),
+		Line:     lexer.line,
+		Column:   lexer.column,
+	}
 }
 
 func (lexer *Lexer) NextToken() token.Token {
 	var tok token.Token
 
 	lexer.skipWhiteSpace()
+	for lexer.mode&ScanComments == 0 && lexer.atComment() {
+		lexer.readComment()
+		lexer.skipWhiteSpace()
+	}
+	pos := lexer.position()
 	currChar := string(lexer.ch)
 
 	switch lexer.ch {
@@ -59,11 +155,22 @@ func (lexer *Lexer) NextToken() token.Token {
 		if lexer.peekChar() == '=' {
 			lexer.readChar()
 			tok = newToken(token.IASTERISK, currChar+string(lexer.ch))
+		} else if lexer.peekChar() == '*' {
+			lexer.readChar()
+			tok = newToken(token.POWER, currChar+string(lexer.ch))
 		} else {
 			tok = newToken(token.ASTERISK, currChar)
 		}
 	case '/':
-		if lexer.peekChar() == '=' {
+		if lexer.atComment() {
+			literal, ok := lexer.readComment()
+			tok = newToken(token.COMMENT, literal)
+			if !ok {
+				tok.Type = token.ILLEGAL
+			}
+			tok.Pos = pos
+			return tok
+		} else if lexer.peekChar() == '=' {
 			lexer.readChar()
 			tok = newToken(token.ISLASH, currChar+string(lexer.ch))
 		} else {
@@ -96,6 +203,26 @@ func (lexer *Lexer) NextToken() token.Token {
 		} else {
 			tok = newToken(token.BANG, currChar)
 		}
+	case '&':
+		if lexer.peekChar() == '&' {
+			lexer.readChar()
+			tok = newToken(token.AND, currChar+string(lexer.ch))
+		} else {
+			tok = newToken(token.AMPERSAND, currChar)
+		}
+	case '|':
+		if lexer.peekChar() == '|' {
+			lexer.readChar()
+			tok = newToken(token.OR, currChar+string(lexer.ch))
+		} else {
+			tok = newToken(token.PIPE, currChar)
+		}
+	case '%':
+		tok = newToken(token.PERCENT, currChar)
+	case '^':
+		tok = newToken(token.CARET, currChar)
+	case '~':
+		tok = newToken(token.TILDE, currChar)
 	case '(':
 		tok = newToken(token.LPAREN, currChar)
 	case ')':
@@ -104,25 +231,58 @@ func (lexer *Lexer) NextToken() token.Token {
 		tok = newToken(token.LBRACE, currChar)
 	case '}':
 		tok = newToken(token.RBRACE, currChar)
+	case '[':
+		tok = newToken(token.LBRACKET, currChar)
+	case ']':
+		tok = newToken(token.RBRACKET, currChar)
+	case ':':
+		tok = newToken(token.COLON, currChar)
 	case ',':
 		tok = newToken(token.COMMA, currChar)
 	case ';':
 		tok = newToken(token.SEMICOLON, currChar)
+	case '"':
+		literal, valid, ok := lexer.readString()
+		if ok {
+			tok = newToken(token.STRING, literal)
+			if !valid {
+				// the escapes were reported; the parser skips ILLEGAL tokens
+				tok.Type = token.ILLEGAL
+			}
+		} else {
+			tok = newToken(token.ILLEGAL, literal)
+			tok.Pos = pos
+			lexer.errors = append(lexer.errors, Error{
+				Start: pos, End: lexer.position(), Message: "unterminated string literal",
+			})
+			return tok
+		}
 	case 0:
-		tok = newToken(token.EOF, "EOF")
+		if lexer.currPosition < len(lexer.input) {
+			tok = newToken(token.ILLEGAL, currChar)
+			lexer.errorf(pos, "unexpected NUL character")
+		} else {
+			tok = newToken(token.EOF, "EOF")
+		}
 	default:
 		if isLetter(lexer.ch) {
 			tok.Literal = lexer.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
+			tok.Pos = pos
 			return tok
-		} else if isDigit(lexer.ch) {
-			tok.Literal = lexer.readNumber()
-			tok.Type = token.INT
+		} else if isDigit(lexer.ch) || lexer.ch == '.' && isDigit(lexer.peekChar()) && !lexer.afterOperand() {
+			tok.Literal, tok.Type = lexer.readNumber()
+			tok.Pos = pos
 			return tok
 		} else {
-			tok = newToken(token.ILLEGAL, "ILLEGAL")
+			tok = newToken(token.ILLEGAL, lexer.input[lexer.currPosition:lexer.nextPosition])
+			// undecodable bytes were reported by readChar
+			if !lexer.invalidEncoding() {
+				lexer.errorf(pos, "unexpected character "+strconv.QuoteRune(lexer.ch))
+			}
 		}
 	}
+	tok.Pos = pos
 	lexer.readChar()
 	return tok
 }
@@ -131,29 +291,255 @@ func newToken(toktype token.TokenType, literal string) token.Token {
 	return token.Token{Type: toktype, Literal: literal}
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+// isLetter reports whether ch may start an identifier.
+func isLetter(ch rune) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' ||
+		ch >= utf8.RuneSelf && unicode.IsLetter(ch)
 }
 
-func isDigit(ch byte) bool {
+// isDigit reports whether ch is a decimal digit. Only ASCII digits start a
+// number.
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
+// isIdentifierChar reports whether ch may continue an identifier. Besides
+// letters and digits this admits combining marks, so that decomposed
+// spellings such as "e\u0301" stay a single identifier.
+func isIdentifierChar(ch rune) bool {
+	return isLetter(ch) || isDigit(ch) ||
+		ch >= utf8.RuneSelf && (unicode.IsDigit(ch) || unicode.In(ch, unicode.Mn, unicode.Mc))
+}
+
 func (lexer *Lexer) readIdentifier() string {
 	startPosition := lexer.currPosition
-	for isLetter(lexer.ch) {
+	for isIdentifierChar(lexer.ch) {
 		lexer.readChar()
 	}
 	return lexer.input[startPosition:lexer.currPosition]
 }
 
-func (lexer *Lexer) readNumber() string {
+// readNumber reads an INT, or a FLOAT if the digits are followed by a
+// fraction or an exponent. A FLOAT may also start with the '.', as in `.5`.
+// Integers may carry a 0x, 0o or 0b base prefix, and digits may be
+// separated by '_'. Malformed literals are returned as ILLEGAL.
+func (lexer *Lexer) readNumber() (string, token.TokenType) {
+	start := lexer.position()
+	startPosition := lexer.currPosition
+
+	if lexer.ch == '0' {
+		if base := basePrefix(lexer.peekChar()); base != 0 {
+			lexer.readChar()
+			lexer.readChar()
+			for isIdentifierChar(lexer.ch) {
+				lexer.readChar()
+			}
+			literal := lexer.input[startPosition:lexer.currPosition]
+			if !lexer.checkNumber(literal, 2, base, start) {
+				return literal, token.ILLEGAL
+			}
+			return literal, token.INT
+		}
+	}
+
+	toktype := token.TokenType(token.INT)
+
+	lexer.readDigits()
+	if lexer.ch == '.' && isDigit(lexer.peekChar()) {
+		toktype = token.FLOAT
+		lexer.readChar()
+		lexer.readDigits()
+	}
+	if lexer.ch == 'e' || lexer.ch == 'E' {
+		toktype = token.FLOAT
+		expStart := lexer.position()
+		lexer.readChar()
+		if lexer.ch == '+' || lexer.ch == '-' {
+			lexer.readChar()
+		}
+		if !isDigit(lexer.ch) {
+			lexer.errorAt(expStart, lexer.position(), "exponent has no digits")
+			return lexer.input[startPosition:lexer.currPosition], token.ILLEGAL
+		}
+		lexer.readDigits()
+	}
+	if lexer.ch == '.' && isDigit(lexer.peekChar()) {
+		// `1.2.3` or `1e5.3`: swallow the rest so it is not read as a
+		// second number
+		lexer.errorf(lexer.position(), "unexpected '.' in number literal")
+		for lexer.ch == '.' && isDigit(lexer.peekChar()) {
+			lexer.readChar()
+			lexer.readDigits()
+		}
+		return lexer.input[startPosition:lexer.currPosition], token.ILLEGAL
+	}
+
+	literal := lexer.input[startPosition:lexer.currPosition]
+	if !lexer.checkNumber(literal, 0, 10, start) {
+		return literal, token.ILLEGAL
+	}
+	return literal, toktype
+}
+
+// afterOperand reports whether the current character directly follows an
+// identifier, a number or a closing ')' or ']', so that a '.' there cannot
+// start a FLOAT like `.5`.
+func (lexer *Lexer) afterOperand() bool {
+	if lexer.currPosition == 0 {
+		return false
+	}
+	prev, _ := utf8.DecodeLastRuneInString(lexer.input[:lexer.currPosition])
+	return isIdentifierChar(prev) || prev == ')' || prev == ']'
+}
+
+func (lexer *Lexer) readDigits() {
+	for isDigit(lexer.ch) || lexer.ch == '_' {
+		lexer.readChar()
+	}
+}
+
+var baseNames = map[int]string{
+	2:  "binary",
+	8:  "octal",
+	10: "decimal",
+	16: "hexadecimal",
+}
+
+// basePrefix returns the base selected by the letter after a leading '0',
+// or 0 if ch does not select one.
+func basePrefix(ch rune) int {
+	switch ch {
+	case 'x', 'X':
+		return 16
+	case 'o', 'O':
+		return 8
+	case 'b', 'B':
+		return 2
+	default:
+		return 0
+	}
+}
+
+// checkNumber validates the digits and '_' separators of a number literal
+// that starts at start, reporting the first offending character. prefixLen
+// is the length of the base prefix, if any.
+func (lexer *Lexer) checkNumber(literal string, prefixLen, base int, start token.Position) bool {
+	at := func(i int) token.Position {
+		pos := start
+		pos.Offset += i
+		pos.Column += i
+		return pos
+	}
+	isDigitOf := func(ch byte) bool {
+		return digitValue(rune(ch)) < base
+	}
+
+	if prefixLen > 0 && strings.Trim(literal[prefixLen:], "_") == "" {
+		lexer.errorAt(start, at(len(literal)), baseNames[base]+" literal has no digits")
+		return false
+	}
+
+	for i, width := prefixLen, 0; i < len(literal); i += width {
+		var ch rune
+		ch, width = utf8.DecodeRuneInString(literal[i:])
+		switch {
+		case ch == '_':
+			prevOK := i == prefixLen && prefixLen > 0 || i > 0 && isDigitOf(literal[i-1])
+			nextOK := i+1 < len(literal) && isDigitOf(literal[i+1])
+			if !prevOK || !nextOK {
+				lexer.errorAt(at(i), at(i+1), "'_' must separate successive digits")
+				return false
+			}
+		case base == 10 && !isDigit(ch):
+			// '.', exponent and sign of a float, already checked when read
+		case digitValue(ch) >= base:
+			lexer.errorAt(at(i), at(i+width), fmt.Sprintf("invalid digit %q in %s literal", ch, baseNames[base]))
+			return false
+		}
+	}
+	return true
+}
+
+// digitValue returns the value of ch as a digit, or 36 if it is not one.
+func digitValue(ch rune) int {
+	switch {
+	case '0' <= ch && ch <= '9':
+		return int(ch - '0')
+	case 'a' <= ch && ch <= 'z':
+		return int(ch-'a') + 10
+	case 'A' <= ch && ch <= 'Z':
+		return int(ch-'A') + 10
+	default:
+		return 36
+	}
+}
+
+// readString reads a double-quoted string starting at the current '"' and
+// returns its spelling, quotes included. Malformed escapes are reported but
+// do not end the string; valid is false if there were any. ok is false if
+// the input ends before the closing quote.
+func (lexer *Lexer) readString() (literal string, valid, ok bool) {
+	startPosition := lexer.currPosition
+	valid = true
+	for {
+		lexer.readChar()
+		switch {
+		case lexer.currPosition >= len(lexer.input):
+			return lexer.input[startPosition:], valid, false
+		case lexer.ch == '"':
+			return lexer.input[startPosition : lexer.currPosition+1], valid, true
+		case lexer.ch == '\\':
+			escStart := lexer.position()
+			_, size, msg := decodeEscape(lexer.input[lexer.currPosition:])
+			// size counts bytes, so step characters until the last one
+			// of the escape is current.
+			escEnd := lexer.currPosition + size
+			for lexer.nextPosition < escEnd {
+				lexer.readChar()
+			}
+			if msg != "" && size > 1 {
+				lexer.errorf(escStart, msg)
+				valid = false
+			}
+		}
+	}
+}
+
+func (lexer *Lexer) atComment() bool {
+	return lexer.ch == '/' && (lexer.peekChar() == '/' || lexer.peekChar() == '*')
+}
+
+// readComment reads a `// line` comment up to the end of the line, or a
+// `/* block */` comment, which may nest. ok is false if a block comment is
+// not closed before the input ends.
+func (lexer *Lexer) readComment() (literal string, ok bool) {
+	start := lexer.position()
 	startPosition := lexer.currPosition
-	for isDigit(lexer.ch) {
+
+	if lexer.peekChar() == '/' {
+		for lexer.ch != '\n' && lexer.currPosition < len(lexer.input) {
+			lexer.readChar()
+		}
+		return lexer.input[startPosition:lexer.currPosition], true
+	}
+
+	lexer.readChar()
+	lexer.readChar()
+	for depth := 1; depth > 0; {
+		switch {
+		case lexer.currPosition >= len(lexer.input):
+			lexer.errorAt(start, lexer.position(), "unterminated block comment")
+			return lexer.input[startPosition:], false
+		case lexer.ch == '/' && lexer.peekChar() == '*':
+			depth += 1
+			lexer.readChar()
+		case lexer.ch == '*' && lexer.peekChar() == '/':
+			depth -= 1
+			lexer.readChar()
+		}
 		lexer.readChar()
 	}
-	got := lexer.input[startPosition:lexer.currPosition]
-	return got
+	return lexer.input[startPosition:lexer.currPosition], true
 }
 
 func (lexer *Lexer) skipWhiteSpace() {
@@ -162,10 +548,10 @@ func (lexer *Lexer) skipWhiteSpace() {
 	}
 }
 
-func (lexer *Lexer) peekChar() byte {
+func (lexer *Lexer) peekChar() rune {
 	if lexer.nextPosition >= len(lexer.input) {
 		return 0
-	} else {
-		return lexer.input[lexer.nextPosition]
 	}
+	ch, _ := utf8.DecodeRuneInString(lexer.input[lexer.nextPosition:])
+	return ch
 }
@@ -15,7 +15,7 @@ let add = fn(x, y) {
 };
 
 let result = add(five, ten);
-!-/*5;
+!-/ *5;
 5 < 10 > 5;
 
 if (5 < 10) {
@@ -26,6 +26,8 @@ if (5 < 10) {
 
 10 == 10;
 10 != 9;
+"foobar"
+"foo bar"
 `
 
 	tests := []struct {
@@ -105,6 +107,8 @@ if (5 < 10) {
 		{token.NE, "!="},
 		{token.INT, "9"},
 		{token.SEMICOLON, ";"},
+		{token.STRING, `"foobar"`},
+		{token.STRING, `"foo bar"`},
 		{token.EOF, "EOF"},
 	}
 	l := New(input)
@@ -120,3 +124,579 @@ if (5 < 10) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5;\n  x >= 10;\n"
+
+	tests := []struct {
+		expectedType   token.TokenType
+		expectedOffset int
+		expectedLine   int
+		expectedColumn int
+	}{
+		{token.LET, 0, 1, 1},
+		{token.IDENT, 4, 1, 5},
+		{token.ASSIGN, 6, 1, 7},
+		{token.INT, 8, 1, 9},
+		{token.SEMICOLON, 9, 1, 10},
+		{token.IDENT, 13, 2, 3},
+		{token.GE, 15, 2, 5},
+		{token.INT, 18, 2, 8},
+		{token.SEMICOLON, 20, 2, 10},
+		{token.EOF, 22, 3, 1},
+	}
+	l := NewFile("main.mk", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] = wrong tokentype. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Pos.Filename != "main.mk" {
+			t.Fatalf("tests[%d] = wrong filename. expected=%q, got=%q", i, "main.mk", tok.Pos.Filename)
+		}
+		if tok.Pos.Offset != tt.expectedOffset {
+			t.Fatalf("tests[%d] = wrong offset. expected=%d, got=%d", i, tt.expectedOffset, tok.Pos.Offset)
+		}
+		if tok.Pos.Line != tt.expectedLine || tok.Pos.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] = wrong position. expected=%d:%d, got=%s",
+				i, tt.expectedLine, tt.expectedColumn, tok.Pos)
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"plain"`, "plain"},
+		{`"a\nb"`, "a\nb"},
+		{`"a\tb"`, "a\tb"},
+		{`"back\\slash"`, `back\slash`},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"\u{41}\u{1F600}"`, "A\U0001F600"},
+		{`""`, ""},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != token.STRING {
+			t.Fatalf("tests[%d] = wrong tokentype. expected=%q, got=%q", i, token.STRING, tok.Type)
+		}
+		if tok.Literal != tt.input {
+			t.Fatalf("tests[%d] = wrong literal. expected=%q, got=%q", i, tt.input, tok.Literal)
+		}
+		if len(l.Errors()) != 0 {
+			t.Fatalf("tests[%d] = unexpected errors: %v", i, l.Errors())
+		}
+
+		value, err := Unquote(tok.Literal)
+		if err != nil {
+			t.Fatalf("tests[%d] = Unquote failed: %s", i, err)
+		}
+		if value != tt.expected {
+			t.Fatalf("tests[%d] = wrong value. expected=%q, got=%q", i, tt.expected, value)
+		}
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedType  token.TokenType
+		expectedMsg   string
+		expectedStart int
+		expectedEnd   int
+	}{
+		{`"abc`, token.ILLEGAL, "unterminated string literal", 0, 4},
+		{`x "a\qb"`, token.ILLEGAL, "unknown escape sequence \\q", 4, 6},
+		{`"\u{110000}"`, token.ILLEGAL, "\\u{110000} is not a valid Unicode code point", 1, 11},
+		{`"\u41"`, token.ILLEGAL, "\\u must be followed by {hex digits}", 1, 3},
+		{`"\é" + "x"`, token.ILLEGAL, "unknown escape sequence \\é", 1, 4},
+		{`"\u{é}" + 1`, token.ILLEGAL, "invalid hex digits in \\u{é}", 1, 7},
+		{`#`, token.ILLEGAL, "unexpected character '#'", 0, 1},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+
+		var tok token.Token
+		for tok = l.NextToken(); tok.Type == token.IDENT; tok = l.NextToken() {
+		}
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] = wrong tokentype. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		for l.NextToken().Type != token.EOF {
+		}
+
+		errors := l.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("tests[%d] = expected 1 error, got=%v", i, errors)
+		}
+		if errors[0].Message != tt.expectedMsg {
+			t.Fatalf("tests[%d] = wrong message. expected=%q, got=%q", i, tt.expectedMsg, errors[0].Message)
+		}
+		if errors[0].Start.Offset != tt.expectedStart || errors[0].End.Offset != tt.expectedEnd {
+			t.Fatalf("tests[%d] = wrong span. expected=[%d, %d), got=[%d, %d)", i,
+				tt.expectedStart, tt.expectedEnd, errors[0].Start.Offset, errors[0].End.Offset)
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	input := `5 3.14 .5 1e-9 2E+10 6e3 1.5e2 1. 1e x.5 (.5) f().5 a[0].5 0xFF 0o755 0b1010 1_000_000 0x_ff 3.141_592 007`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "5"},
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, ".5"},
+		{token.FLOAT, "1e-9"},
+		{token.FLOAT, "2E+10"},
+		{token.FLOAT, "6e3"},
+		{token.FLOAT, "1.5e2"},
+		{token.INT, "1"},
+		{token.ILLEGAL, "."},
+		{token.ILLEGAL, "1e"},
+		{token.IDENT, "x"},
+		{token.ILLEGAL, "."},
+		{token.INT, "5"},
+		{token.LPAREN, "("},
+		{token.FLOAT, ".5"},
+		{token.RPAREN, ")"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.RPAREN, ")"},
+		{token.ILLEGAL, "."},
+		{token.INT, "5"},
+		{token.IDENT, "a"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.ILLEGAL, "."},
+		{token.INT, "5"},
+		{token.INT, "0xFF"},
+		{token.INT, "0o755"},
+		{token.INT, "0b1010"},
+		{token.INT, "1_000_000"},
+		{token.INT, "0x_ff"},
+		{token.FLOAT, "3.141_592"},
+		{token.INT, "007"},
+		{token.EOF, "EOF"},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] = wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] = wrong tokentype. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+	}
+}
+
+func TestNumberErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedMsg   string
+		expectedStart int
+		expectedEnd   int
+	}{
+		{"0xZZ", "invalid digit 'Z' in hexadecimal literal", 2, 3},
+		{"0o758", "invalid digit '8' in octal literal", 4, 5},
+		{"0b102", "invalid digit '2' in binary literal", 4, 5},
+		{"0x", "hexadecimal literal has no digits", 0, 2},
+		{"0b__", "binary literal has no digits", 0, 4},
+		{"1__000", "'_' must separate successive digits", 1, 2},
+		{"1000_", "'_' must separate successive digits", 4, 5},
+		{"1_.5", "'_' must separate successive digits", 1, 2},
+		{"0xF_", "'_' must separate successive digits", 3, 4},
+		{"1e", "exponent has no digits", 1, 2},
+		{"1.5e+", "exponent has no digits", 3, 5},
+		{"1.2.3", "unexpected '.' in number literal", 3, 4},
+		{"1e5.3.4", "unexpected '.' in number literal", 3, 4},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != token.ILLEGAL {
+			t.Fatalf("tests[%d] = wrong tokentype. expected=%q, got=%q", i, token.ILLEGAL, tok.Type)
+		}
+		if tok.Literal != tt.input {
+			t.Fatalf("tests[%d] = wrong literal. expected=%q, got=%q", i, tt.input, tok.Literal)
+		}
+
+		errors := l.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("tests[%d] = expected 1 error, got=%v", i, errors)
+		}
+		if errors[0].Message != tt.expectedMsg {
+			t.Fatalf("tests[%d] = wrong message. expected=%q, got=%q", i, tt.expectedMsg, errors[0].Message)
+		}
+		if errors[0].Start.Offset != tt.expectedStart || errors[0].End.Offset != tt.expectedEnd {
+			t.Fatalf("tests[%d] = wrong span. expected=[%d, %d), got=[%d, %d)", i,
+				tt.expectedStart, tt.expectedEnd, errors[0].Start.Offset, errors[0].End.Offset)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading comment
+let x = 10 / 2; // trailing comment
+/* block /* nested */ still comment */ x /= 2;
+/**/`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.COMMENT, "// leading comment"},
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "10"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.COMMENT, "// trailing comment"},
+		{token.COMMENT, "/* block /* nested */ still comment */"},
+		{token.IDENT, "x"},
+		{token.ISLASH, "/="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.COMMENT, "/**/"},
+		{token.EOF, "EOF"},
+	}
+
+	// comments are only returned in ScanComments mode
+	skipped := New(input)
+	scanned := NewFileWithMode("", input, ScanComments)
+
+	for i, tt := range tests {
+		tok := scanned.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] = wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] = wrong tokentype. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tt.expectedType == token.COMMENT {
+			continue
+		}
+
+		tok = skipped.NextToken()
+		if tok.Literal != tt.expectedLiteral || tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] = wrong token when skipping comments. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestUnterminatedComment(t *testing.T) {
+	input := "x /* open /* nested */"
+
+	for _, mode := range []Mode{0, ScanComments} {
+		l := NewFileWithMode("", input, mode)
+		l.NextToken()
+
+		tok := l.NextToken()
+		expectedType := token.TokenType(token.EOF)
+		if mode == ScanComments {
+			expectedType = token.ILLEGAL
+		}
+		if tok.Type != expectedType {
+			t.Fatalf("mode %d: wrong tokentype. expected=%q, got=%q", mode, expectedType, tok.Type)
+		}
+
+		errors := l.Errors()
+		if len(errors) != 1 || errors[0].Message != "unterminated block comment" {
+			t.Fatalf("mode %d: expected unterminated block comment error, got=%v", mode, errors)
+		}
+		if errors[0].Start.Offset != 2 || errors[0].End.Offset != len(input) {
+			t.Fatalf("mode %d: wrong span. expected=[2, %d), got=[%d, %d)", mode,
+				len(input), errors[0].Start.Offset, errors[0].End.Offset)
+		}
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	input := "x1 naïve _tmp2 __ Ωmega 变量 x١ 1x let2 fn_ é cafe\u0301s"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "x1"},
+		{token.IDENT, "naïve"},
+		{token.IDENT, "_tmp2"},
+		{token.IDENT, "__"},
+		{token.IDENT, "Ωmega"},
+		{token.IDENT, "变量"},
+		{token.IDENT, "x١"},
+		{token.INT, "1"},
+		{token.IDENT, "x"},
+		{token.IDENT, "let2"},
+		{token.IDENT, "fn_"},
+		{token.IDENT, "é"},
+		{token.IDENT, "cafe\u0301s"},
+		{token.EOF, "EOF"},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] = wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] = wrong tokentype. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+	}
+
+	if len(l.Errors()) != 0 {
+		t.Fatalf("unexpected errors: %v", l.Errors())
+	}
+}
+
+func TestUTF8(t *testing.T) {
+	input := "let 名前 = \"héllo 🌍 世界\"; // 注释 🎉\nlet cafe\u0301 = \"e\u0301\"; 😀 名前"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedOffset  int
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{token.LET, "let", 0, 1, 1},
+		{token.IDENT, "名前", 4, 1, 5},
+		{token.ASSIGN, "=", 11, 1, 8},
+		{token.STRING, "\"héllo 🌍 世界\"", 13, 1, 10},
+		{token.SEMICOLON, ";", 33, 1, 22},
+		{token.LET, "let", 50, 2, 1},
+		{token.IDENT, "cafe\u0301", 54, 2, 5},
+		{token.ASSIGN, "=", 61, 2, 11},
+		{token.STRING, "\"e\u0301\"", 63, 2, 13},
+		{token.SEMICOLON, ";", 68, 2, 17},
+		{token.ILLEGAL, "😀", 70, 2, 19},
+		{token.IDENT, "名前", 75, 2, 21},
+		{token.EOF, "EOF", 81, 2, 23},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] = wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] = wrong tokentype. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Pos.Offset != tt.expectedOffset {
+			t.Fatalf("tests[%d] = wrong offset. expected=%d, got=%d", i, tt.expectedOffset, tok.Pos.Offset)
+		}
+		if tok.Pos.Line != tt.expectedLine || tok.Pos.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] = wrong position. expected=%d:%d, got=%s",
+				i, tt.expectedLine, tt.expectedColumn, tok.Pos)
+		}
+	}
+
+	errors := l.Errors()
+	if len(errors) != 1 || errors[0].Message != "unexpected character '😀'" {
+		t.Fatalf("expected one unexpected character error, got=%v", errors)
+	}
+	if errors[0].Start.Offset != 70 || errors[0].End.Offset != 74 {
+		t.Fatalf("wrong span. expected=[70, 74), got=[%d, %d)", errors[0].Start.Offset, errors[0].End.Offset)
+	}
+
+	value, err := Unquote("\"héllo 🌍 世界\"")
+	if err != nil || value != "héllo 🌍 世界" {
+		t.Fatalf("wrong unquoted value. got=%q (%v)", value, err)
+	}
+}
+
+func TestInvalidEncoding(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedMsg string
+		expected    []token.TokenType
+	}{
+		{"x \xff y", "invalid UTF-8 encoding", []token.TokenType{token.IDENT, token.ILLEGAL, token.IDENT}},
+		{"\"a\xffb\"", "invalid UTF-8 encoding", []token.TokenType{token.STRING}},
+		{"x \x00 y", "unexpected NUL character", []token.TokenType{token.IDENT, token.ILLEGAL, token.IDENT}},
+		{"\uFEFFlet", "", []token.TokenType{token.LET}},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+
+		for j, expectedType := range tt.expected {
+			tok := l.NextToken()
+			if tok.Type != expectedType {
+				t.Fatalf("tests[%d][%d] = wrong tokentype. expected=%q, got=%q", i, j, expectedType, tok.Type)
+			}
+		}
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Fatalf("tests[%d] = expected EOF, got=%q", i, tok.Type)
+		}
+
+		errors := l.Errors()
+		if tt.expectedMsg == "" {
+			if len(errors) != 0 {
+				t.Fatalf("tests[%d] = unexpected errors: %v", i, errors)
+			}
+			continue
+		}
+		if len(errors) != 1 || errors[0].Message != tt.expectedMsg {
+			t.Fatalf("tests[%d] = expected %q, got=%v", i, tt.expectedMsg, errors)
+		}
+	}
+}
+
+func TestBrackets(t *testing.T) {
+	input := `[1, 2][0]{"a": 1}`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.LBRACE, "{"},
+		{token.STRING, `"a"`},
+		{token.COLON, ":"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.EOF, "EOF"},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] = wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] = wrong tokentype. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	input := `a && b || !c`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.AND, "&&"},
+		{token.IDENT, "b"},
+		{token.OR, "||"},
+		{token.BANG, "!"},
+		{token.IDENT, "c"},
+		{token.EOF, "EOF"},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] = wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] = wrong tokentype. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+	}
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	input := `a & b | c ^ ~d % e && f || g`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "b"},
+		{token.PIPE, "|"},
+		{token.IDENT, "c"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.IDENT, "d"},
+		{token.PERCENT, "%"},
+		{token.IDENT, "e"},
+		{token.AND, "&&"},
+		{token.IDENT, "f"},
+		{token.OR, "||"},
+		{token.IDENT, "g"},
+		{token.EOF, "EOF"},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] = wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] = wrong tokentype. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+	}
+}
+
+func TestPowerOperator(t *testing.T) {
+	input := `2 ** 3 * 4 *= 5`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "2"},
+		{token.POWER, "**"},
+		{token.INT, "3"},
+		{token.ASTERISK, "*"},
+		{token.INT, "4"},
+		{token.IASTERISK, "*="},
+		{token.INT, "5"},
+		{token.EOF, "EOF"},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] = wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] = wrong tokentype. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package object
+
+// Environment maps names to values. Each function call gets its own
+// Environment enclosing the one the function was defined in, so lookups
+// walk outward through the lexical scopes.
+type Environment struct {
+	store map[string]Object
+	outer *Environment
+}
+
+func NewEnvironment() *Environment {
+	return &Environment{store: make(map[string]Object)}
+}
+
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	env := NewEnvironment()
+	env.outer = outer
+	return env
+}
+
+func (env *Environment) Get(name string) (Object, bool) {
+	obj, ok := env.store[name]
+	if !ok && env.outer != nil {
+		return env.outer.Get(name)
+	}
+	return obj, ok
+}
+
+// Set binds name in this scope, shadowing any outer binding.
+func (env *Environment) Set(name string, val Object) Object {
+	env.store[name] = val
+	return val
+}
+
+// Assign rebinds name in the innermost scope that already defines it. It
+// reports false if name is not bound anywhere.
+func (env *Environment) Assign(name string, val Object) bool {
+	if _, ok := env.store[name]; ok {
+		env.store[name] = val
+		return true
+	}
+	if env.outer != nil {
+		return env.outer.Assign(name, val)
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sayandipdutta/monkey/ast"
+)
+
+type ObjectType string
+
+const (
+	INTEGER_OBJ      = "INTEGER"
+	BIG_INTEGER_OBJ  = "BIG_INTEGER"
+	FLOAT_OBJ        = "FLOAT"
+	BOOLEAN_OBJ      = "BOOLEAN"
+	STRING_OBJ       = "STRING"
+	NULL_OBJ         = "NULL"
+	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	ERROR_OBJ        = "ERROR"
+	FUNCTION_OBJ     = "FUNCTION"
+	ARRAY_OBJ        = "ARRAY"
+	HASH_OBJ         = "HASH"
+)
+
+type Object interface {
+	Type() ObjectType
+	Inspect() string
+}
+
+// Integer
+type Integer struct {
+	Value int64
+}
+
+func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
+func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
+
+// BigInteger holds integers that do not fit in an Integer.
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (bi *BigInteger) Type() ObjectType { return BIG_INTEGER_OBJ }
+func (bi *BigInteger) Inspect() string  { return bi.Value.String() }
+
+// Float
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+// Inspect always shows a fraction or an exponent so that floats are not
+// mistaken for integers.
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+
+// Boolean
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
+func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
+
+// String
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) Inspect() string  { return s.Value }
+
+// Null
+type Null struct{}
+
+func (n *Null) Type() ObjectType { return NULL_OBJ }
+func (n *Null) Inspect() string  { return "null" }
+
+// ReturnValue wraps the value of a `return` statement while it unwinds
+// through enclosing blocks.
+type ReturnValue struct {
+	Value Object
+}
+
+func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
+func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
+
+// Error
+type Error struct {
+	Message string
+}
+
+func (e *Error) Type() ObjectType { return ERROR_OBJ }
+func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
+
+// Function is a function value; Env is the environment it was defined in.
+type Function struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
+func (f *Function) Inspect() string {
+	var out bytes.Buffer
+
+	params := make([]string, 0, len(f.Parameters))
+	for _, p := range f.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("fn(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(f.Body.String())
+	out.WriteString("\n}")
+	return out.String()
+}
+
+// Array
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType { return ARRAY_OBJ }
+func (a *Array) Inspect() string {
+	elements := make([]string, 0, len(a.Elements))
+	for _, el := range a.Elements {
+		elements = append(elements, el.Inspect())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// HashKey identifies a Hashable value: equal values of the same type have
+// equal keys, and different values have different keys. Strings are keyed
+// by their contents, so they cannot collide.
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+	Str   string
+}
+
+// Hashable is implemented by the objects that may be used as hash keys.
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+func (s *String) HashKey() HashKey {
+	return HashKey{Type: s.Type(), Str: s.Value}
+}
+
+// less orders keys by type, then by value; integers compare numerically.
+func (k HashKey) less(other HashKey) bool {
+	switch {
+	case k.Type != other.Type:
+		return k.Type < other.Type
+	case k.Type == INTEGER_OBJ:
+		return int64(k.Value) < int64(other.Value)
+	case k.Value != other.Value:
+		return k.Value < other.Value
+	default:
+		return k.Str < other.Str
+	}
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash
+type Hash struct {
+	Pairs map[HashKey]HashPair
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+
+// Inspect lists the pairs sorted by key so that the output is stable.
+// String keys are quoted to tell "1" apart from 1.
+func (h *Hash) Inspect() string {
+	keys := make([]HashKey, 0, len(h.Pairs))
+	for key := range h.Pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].less(keys[j]) })
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pair := h.Pairs[key]
+		name := pair.Key.Inspect()
+		if key, ok := pair.Key.(*String); ok {
+			name = strconv.Quote(key.Value)
+		}
+		pairs = append(pairs, name+": "+pair.Value.Inspect())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sayandipdutta/monkey/token"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityNote
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityNote:
+		return "note"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// ErrorCode identifies the kind of a Diagnostic independently of its message.
+type ErrorCode string
+
+const (
+	ErrUnexpectedToken     ErrorCode = "E0001"
+	ErrNoPrefixParseFn     ErrorCode = "E0002"
+	ErrInvalidInteger      ErrorCode = "E0003"
+	ErrInvalidAssignTarget ErrorCode = "E0004"
+	ErrInvalidToken        ErrorCode = "E0005"
+	ErrInvalidFloat        ErrorCode = "E0006"
+	ErrIntegerOverflow     ErrorCode = "E0007"
+)
+
+// Span is the half-open source range [Start, End) a Diagnostic refers to.
+type Span struct {
+	Start token.Position
+	End   token.Position
+}
+
+// spanOf returns the span covered by tok. Strings and block comments may
+// contain newlines, in which case the end is on a later line.
+func spanOf(tok token.Token) Span {
+	end := tok.Pos
+	if tok.Type == token.EOF {
+		return Span{Start: tok.Pos, End: end}
+	}
+	end.Offset += len(tok.Literal)
+	if i := strings.LastIndexByte(tok.Literal, '\n'); i >= 0 {
+		end.Line += strings.Count(tok.Literal, "\n")
+		end.Column = 1 + utf8.RuneCountInString(tok.Literal[i+1:])
+	} else {
+		end.Column += utf8.RuneCountInString(tok.Literal)
+	}
+	return Span{Start: tok.Pos, End: end}
+}
+
+type Diagnostic struct {
+	Severity Severity
+	Code     ErrorCode
+	Span     Span
+	Expected []token.TokenType
+	Actual   token.TokenType
+	Message  string
+	Hint     string
+}
+
+func (diag *Diagnostic) Error() string {
+	return fmt.Sprintf("%s: %s", diag.Span.Start, diag.Message)
+}
@@ -1,34 +1,74 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
+	"strings"
 
 	"github.com/sayandipdutta/monkey/ast"
 	"github.com/sayandipdutta/monkey/lexer"
 	"github.com/sayandipdutta/monkey/token"
 )
 
+// Binary operators follow Go's precedence table: shifts and `&` bind like
+// `*`, and `|` and `^` like `+`. There is deliberately no separate shift
+// level between SUM and LESSGREATER as in C, so `1 << 2 | 1` is 5 and
+// `a << 1 + 2` is `(a << 1) + 2`.
 const (
 	_ int = iota
 	LOWEST
+	LOGICALOR   // ||
+	LOGICALAND  // &&
 	EQUALS      // ==
-	LESSGREATER // < or >
-	SUM         // +
-	PRODUCT     // *
-	PREFIX      // -X or !X
+	LESSGREATER // <, >, <= or >=
+	SUM         // +, -, | or ^
+	PRODUCT     // *, /, %, &, << or >>
+	PREFIX      // -X, !X or ~X
+	POWER       // **
 	CALL        // myFunction(X)
+	INDEX       // array[index]
 )
 
+var assignOperators = map[token.TokenType]bool{
+	token.ASSIGN:    true,
+	token.IPLUS:     true,
+	token.IMINUS:    true,
+	token.IASTERISK: true,
+	token.ISLASH:    true,
+}
+
+// rightAssociative lists the infix operators that group from the right, so
+// that `a ** b ** c` parses as `a ** (b ** c)`. All others group from the
+// left.
+var rightAssociative = map[token.TokenType]bool{
+	token.POWER: true,
+}
+
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NE:       EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
+	token.OR:        LOGICALOR,
+	token.AND:       LOGICALAND,
+	token.EQ:        EQUALS,
+	token.NE:        EQUALS,
+	token.LT:        LESSGREATER,
+	token.GT:        LESSGREATER,
+	token.LE:        LESSGREATER,
+	token.GE:        LESSGREATER,
+	token.PLUS:      SUM,
+	token.MINUS:     SUM,
+	token.PIPE:      SUM,
+	token.CARET:     SUM,
+	token.SLASH:     PRODUCT,
+	token.ASTERISK:  PRODUCT,
+	token.PERCENT:   PRODUCT,
+	token.AMPERSAND: PRODUCT,
+	token.LSHIFT:    PRODUCT,
+	token.RSHIFT:    PRODUCT,
+	token.POWER:     POWER,
+	token.LPAREN:    CALL,
+	token.LBRACKET:  INDEX,
 }
 
 type (
@@ -36,19 +76,36 @@ type (
 	infixParseFn  func(ast.Expression) ast.Expression
 )
 
+// Mode is a set of flags that change how the parser behaves.
+type Mode uint
+
+const (
+	// BigIntegers makes integer literals that do not fit in an int64 parse
+	// as arbitrary-precision ast.BigIntegerLiteral nodes instead of
+	// reporting an overflow.
+	BigIntegers Mode = 1 << iota
+)
+
 type Parser struct {
 	lexer          *lexer.Lexer
+	mode           Mode
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 	currToken      token.Token
 	peekToken      token.Token
-	Errors         []string
+	lexErrors      int
+	Diagnostics    []*Diagnostic
 }
 
 func New(lexer *lexer.Lexer) *Parser {
+	return NewWithMode(lexer, 0)
+}
+
+func NewWithMode(lexer *lexer.Lexer, mode Mode) *Parser {
 	parser := &Parser{
-		lexer:  lexer,
-		Errors: []string{},
+		lexer:       lexer,
+		mode:        mode,
+		Diagnostics: []*Diagnostic{},
 	}
 
 	parser.nextToken()
@@ -57,18 +114,41 @@ func New(lexer *lexer.Lexer) *Parser {
 	parser.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	parser.registerPrefixFn(token.IDENT, parser.parseIdentifier)
 	parser.registerPrefixFn(token.INT, parser.parseIntLiteral)
+	parser.registerPrefixFn(token.FLOAT, parser.parseFloatLiteral)
+	parser.registerPrefixFn(token.STRING, parser.parseStringLiteral)
+	parser.registerPrefixFn(token.TRUE, parser.parseBoolean)
+	parser.registerPrefixFn(token.FALSE, parser.parseBoolean)
 	parser.registerPrefixFn(token.MINUS, parser.parsePrefixExpression)
 	parser.registerPrefixFn(token.BANG, parser.parsePrefixExpression)
+	parser.registerPrefixFn(token.TILDE, parser.parsePrefixExpression)
+	parser.registerPrefixFn(token.LPAREN, parser.parseGroupedExpression)
+	parser.registerPrefixFn(token.IF, parser.parseIfExpression)
+	parser.registerPrefixFn(token.FUNCTION, parser.parseFunctionLiteral)
+	parser.registerPrefixFn(token.LBRACKET, parser.parseArrayLiteral)
+	parser.registerPrefixFn(token.LBRACE, parser.parseHashLiteral)
 
 	parser.infixParseFns = make(map[token.TokenType]infixParseFn)
 	parser.registerInfixFn(token.EQ, parser.parseInfixExpression)
 	parser.registerInfixFn(token.NE, parser.parseInfixExpression)
 	parser.registerInfixFn(token.GT, parser.parseInfixExpression)
 	parser.registerInfixFn(token.LT, parser.parseInfixExpression)
+	parser.registerInfixFn(token.LE, parser.parseInfixExpression)
+	parser.registerInfixFn(token.GE, parser.parseInfixExpression)
+	parser.registerInfixFn(token.LSHIFT, parser.parseInfixExpression)
+	parser.registerInfixFn(token.RSHIFT, parser.parseInfixExpression)
 	parser.registerInfixFn(token.PLUS, parser.parseInfixExpression)
 	parser.registerInfixFn(token.MINUS, parser.parseInfixExpression)
 	parser.registerInfixFn(token.SLASH, parser.parseInfixExpression)
 	parser.registerInfixFn(token.ASTERISK, parser.parseInfixExpression)
+	parser.registerInfixFn(token.PERCENT, parser.parseInfixExpression)
+	parser.registerInfixFn(token.AMPERSAND, parser.parseInfixExpression)
+	parser.registerInfixFn(token.PIPE, parser.parseInfixExpression)
+	parser.registerInfixFn(token.CARET, parser.parseInfixExpression)
+	parser.registerInfixFn(token.POWER, parser.parseInfixExpression)
+	parser.registerInfixFn(token.AND, parser.parseLogicalExpression)
+	parser.registerInfixFn(token.OR, parser.parseLogicalExpression)
+	parser.registerInfixFn(token.LPAREN, parser.parseCallExpression)
+	parser.registerInfixFn(token.LBRACKET, parser.parseIndexExpression)
 
 	return parser
 }
@@ -76,6 +156,26 @@ func New(lexer *lexer.Lexer) *Parser {
 func (p *Parser) nextToken() {
 	p.currToken = p.peekToken
 	p.peekToken = p.lexer.NextToken()
+	for p.peekToken.Type == token.COMMENT {
+		p.peekToken = p.lexer.NextToken()
+	}
+	p.reportLexErrors()
+}
+
+// reportLexErrors turns errors the lexer found since the last call into
+// diagnostics.
+func (p *Parser) reportLexErrors() {
+	errors := p.lexer.Errors()
+	for _, err := range errors[p.lexErrors:] {
+		p.Diagnostics = append(p.Diagnostics, &Diagnostic{
+			Severity: SeverityError,
+			Code:     ErrInvalidToken,
+			Span:     Span{Start: err.Start, End: err.End},
+			Actual:   token.ILLEGAL,
+			Message:  err.Message,
+		})
+	}
+	p.lexErrors = len(errors)
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -83,18 +183,24 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program.Statements = []ast.Statement{}
 
 	for p.currToken.Type != token.EOF {
-		stmt := p.parseStatement()
-		program.Statements = append(program.Statements, stmt)
+		if stmt := p.parseStatement(); stmt != nil {
+			program.Statements = append(program.Statements, stmt)
+		}
 		p.nextToken()
 	}
 
 	return program
 }
 
+// parseStatement returns nil if the statement could not be parsed at all.
 func (parser *Parser) parseStatement() ast.Statement {
 	switch parser.currToken.Type {
 	case token.LET:
-		return parser.parseLetStatement()
+		// avoid wrapping a nil *ast.LetStatment in a non-nil interface
+		if stmt := parser.parseLetStatement(); stmt != nil {
+			return stmt
+		}
+		return nil
 	case token.RETURN:
 		return parser.parseReturnStatement()
 	default:
@@ -105,20 +211,23 @@ func (parser *Parser) parseStatement() ast.Statement {
 func (parser *Parser) parseLetStatement() *ast.LetStatment {
 	letstmt := &ast.LetStatment{Token: parser.currToken}
 
-	// TODO: store parseErrors
 	if !parser.expectPeek(token.IDENT) {
 		return nil
 	}
 
 	letstmt.Name = &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
 
-	// TODO: store parseErrors
 	if !parser.expectPeek(token.ASSIGN) {
+		if !parser.peekTokenIs(token.ILLEGAL) {
+			parser.lastDiagnostic().Hint = fmt.Sprintf("did you forget `=` after `let %s`?", letstmt.Name.Value)
+		}
 		return nil
 	}
 
-	// TODO: Parse expressions
-	for !parser.currTokenIs(token.SEMICOLON) {
+	parser.nextToken()
+	letstmt.Value = parser.parseExpression(LOWEST)
+
+	if parser.peekTokenIs(token.SEMICOLON) {
 		parser.nextToken()
 	}
 	return letstmt
@@ -126,24 +235,71 @@ func (parser *Parser) parseLetStatement() *ast.LetStatment {
 
 func (parser *Parser) parseReturnStatement() *ast.ReturnStatement {
 	retstmt := &ast.ReturnStatement{Token: parser.currToken}
+
+	// bare `return;`, or `return` at the end of a block or the input
+	if parser.peekTokenIs(token.SEMICOLON) || parser.peekTokenIs(token.RBRACE) || parser.peekTokenIs(token.EOF) {
+		if parser.peekTokenIs(token.SEMICOLON) {
+			parser.nextToken()
+		}
+		return retstmt
+	}
+
 	parser.nextToken()
+	retstmt.Value = parser.parseExpression(LOWEST)
 
-	for !parser.currTokenIs(token.SEMICOLON) {
+	if parser.peekTokenIs(token.SEMICOLON) {
 		parser.nextToken()
 	}
 	return retstmt
 }
 
-func (parser *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+func (parser *Parser) parseExpressionStatement() ast.Statement {
 	expst := &ast.ExpressionStatement{Token: parser.currToken}
 	expst.Expression = parser.parseExpression(LOWEST)
 
+	if assignOperators[parser.peekToken.Type] {
+		return parser.parseAssignStatement(expst.Expression)
+	}
+
 	if parser.peekTokenIs(token.SEMICOLON) {
 		parser.nextToken()
 	}
 	return expst
 }
 
+func (parser *Parser) parseAssignStatement(target ast.Expression) *ast.AssignStatement {
+	parser.nextToken()
+	stmt := &ast.AssignStatement{
+		Token:    parser.currToken,
+		Target:   target,
+		Operator: parser.currToken.Literal,
+	}
+
+	// a nil target failed to parse and has been reported already
+	if target != nil && !isAssignable(target) {
+		diag := parser.errorAt(stmt.Token, ErrInvalidAssignTarget,
+			"cannot assign to `%s`", target.String())
+		diag.Hint = fmt.Sprintf("the left-hand side of `%s` must be an identifier or an index expression", stmt.Operator)
+	}
+
+	parser.nextToken()
+	stmt.Value = parser.parseExpression(LOWEST)
+
+	if parser.peekTokenIs(token.SEMICOLON) {
+		parser.nextToken()
+	}
+	return stmt
+}
+
+func isAssignable(expr ast.Expression) bool {
+	switch expr.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+		return true
+	default:
+		return false
+	}
+}
+
 func (parser *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := parser.prefixParseFns[parser.currToken.Type]
 	if prefix == nil {
@@ -164,11 +320,39 @@ func (parser *Parser) parseExpression(precedence int) ast.Expression {
 	return leftExp
 }
 
+// Errors returns the rendered message of every diagnostic, in the order they
+// were reported.
+func (parser *Parser) Errors() []string {
+	errors := make([]string, 0, len(parser.Diagnostics))
+	for _, diag := range parser.Diagnostics {
+		errors = append(errors, diag.Error())
+	}
+	return errors
+}
+
+func (parser *Parser) errorAt(tok token.Token, code ErrorCode, format string, args ...any) *Diagnostic {
+	diag := &Diagnostic{
+		Severity: SeverityError,
+		Code:     code,
+		Span:     spanOf(tok),
+		Actual:   tok.Type,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	parser.Diagnostics = append(parser.Diagnostics, diag)
+	return diag
+}
+
+func (parser *Parser) lastDiagnostic() *Diagnostic {
+	return parser.Diagnostics[len(parser.Diagnostics)-1]
+}
+
 func (parser *Parser) noPrefixParseFnError() {
-	msg := fmt.Sprintf(
-		"No prefix functions found for token: %s", parser.currToken.Type,
-	)
-	parser.Errors = append(parser.Errors, msg)
+	// the lexer has already reported why the token is illegal
+	if parser.currTokenIs(token.ILLEGAL) {
+		return
+	}
+	parser.errorAt(parser.currToken, ErrNoPrefixParseFn,
+		"No prefix functions found for token: %s", parser.currToken.Type)
 }
 
 func (parser *Parser) parseIdentifier() ast.Expression {
@@ -179,10 +363,22 @@ func (parser *Parser) parseIdentifier() ast.Expression {
 }
 
 func (parser *Parser) parseIntLiteral() ast.Expression {
-	value, err := strconv.ParseInt(parser.currToken.Literal, 10, 64)
+	literal := parser.currToken.Literal
+	digits, base := splitIntLiteral(literal)
+	value, err := strconv.ParseInt(digits, base, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if parser.mode&BigIntegers != 0 {
+			if value, ok := new(big.Int).SetString(digits, base); ok {
+				return &ast.BigIntegerLiteral{Token: parser.currToken, Value: value}
+			}
+		}
+		parser.errorAt(parser.currToken, ErrIntegerOverflow,
+			"integer literal %s overflows int64; valid range is %d to %d",
+			literal, math.MinInt64, math.MaxInt64)
+		return nil
+	}
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as argument", parser.currToken.Type)
-		parser.Errors = append(parser.Errors, msg)
+		parser.errorAt(parser.currToken, ErrInvalidInteger, "invalid integer literal %s", literal)
 		return nil
 	}
 	return &ast.IntegerLiteral{
@@ -191,16 +387,284 @@ func (parser *Parser) parseIntLiteral() ast.Expression {
 	}
 }
 
+// splitIntLiteral returns the digits of an INT token literal, without base
+// prefix and '_' separators, along with its base. A leading zero without a
+// prefix does not make a literal octal.
+func splitIntLiteral(literal string) (string, int) {
+	digits := strings.ReplaceAll(literal, "_", "")
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			return digits[2:], 16
+		case 'o', 'O':
+			return digits[2:], 8
+		case 'b', 'B':
+			return digits[2:], 2
+		}
+	}
+	return digits, 10
+}
+
+func (parser *Parser) parseFloatLiteral() ast.Expression {
+	value, err := strconv.ParseFloat(strings.ReplaceAll(parser.currToken.Literal, "_", ""), 64)
+	if err != nil {
+		parser.errorAt(parser.currToken, ErrInvalidFloat,
+			"float literal %s is out of range", parser.currToken.Literal)
+		return nil
+	}
+	return &ast.FloatLiteral{
+		Token: parser.currToken,
+		Value: value,
+	}
+}
+
+func (parser *Parser) parseStringLiteral() ast.Expression {
+	value, err := lexer.Unquote(parser.currToken.Literal)
+	if err != nil {
+		parser.errorAt(parser.currToken, ErrInvalidToken, "%s", err)
+		return nil
+	}
+	return &ast.StringLiteral{Token: parser.currToken, Value: value}
+}
+
+func (parser *Parser) parseBoolean() ast.Expression {
+	return &ast.Boolean{
+		Token: parser.currToken,
+		Value: parser.currTokenIs(token.TRUE),
+	}
+}
+
 func (parser *Parser) parsePrefixExpression() ast.Expression {
 	expr := &ast.PrefixExpression{
 		Token:    parser.currToken,
 		Operator: parser.currToken.Literal,
 	}
 	parser.nextToken()
+
+	// The minus sign is not part of an INT literal, so the magnitude of
+	// math.MinInt64 does not fit in an int64 by itself. When the minus
+	// applies to the literal alone, fold both into one literal.
+	if expr.Operator == "-" && parser.currTokenIs(token.INT) && parser.peekPrecedence() <= PREFIX {
+		digits, base := splitIntLiteral(parser.currToken.Literal)
+		if value, err := strconv.ParseUint(digits, base, 64); err == nil && value == 1<<63 {
+			tok := parser.currToken
+			tok.Literal = expr.Operator + tok.Literal
+			tok.Pos = expr.Token.Pos
+			return &ast.IntegerLiteral{Token: tok, Value: math.MinInt64}
+		}
+	}
+
 	expr.Right = parser.parseExpression(PREFIX)
 	return expr
 }
 
+func (parser *Parser) parseGroupedExpression() ast.Expression {
+	parser.nextToken()
+
+	expr := parser.parseExpression(LOWEST)
+	if !parser.expectPeek(token.RPAREN) {
+		return nil
+	}
+	return expr
+}
+
+func (parser *Parser) parseIfExpression() ast.Expression {
+	expr := &ast.IfExpression{Token: parser.currToken}
+
+	if !parser.expectPeek(token.LPAREN) {
+		return nil
+	}
+	parser.nextToken()
+	expr.Condition = parser.parseExpression(LOWEST)
+
+	if !parser.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !parser.expectPeek(token.LBRACE) {
+		return nil
+	}
+	expr.Consequence = parser.parseBlockStatement()
+
+	if !parser.peekTokenIs(token.ELSE) {
+		return expr
+	}
+	parser.nextToken()
+
+	if parser.peekTokenIs(token.IF) {
+		parser.nextToken()
+		ifToken := parser.currToken
+		elseIf := parser.parseIfExpression()
+		if elseIf == nil {
+			return nil
+		}
+		expr.Alternative = &ast.BlockStatement{
+			Token: ifToken,
+			Statements: []ast.Statement{
+				&ast.ExpressionStatement{Token: ifToken, Expression: elseIf},
+			},
+		}
+		return expr
+	}
+
+	if !parser.expectPeek(token.LBRACE) {
+		return nil
+	}
+	expr.Alternative = parser.parseBlockStatement()
+	return expr
+}
+
+func (parser *Parser) parseBlockStatement() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: parser.currToken}
+	block.Statements = []ast.Statement{}
+
+	parser.nextToken()
+	for !parser.currTokenIs(token.RBRACE) {
+		if parser.currTokenIs(token.EOF) {
+			diag := parser.errorAt(parser.currToken, ErrUnexpectedToken,
+				"expected %s to close block, got %s", token.RBRACE, parser.currToken.Type)
+			diag.Expected = []token.TokenType{token.RBRACE}
+			return block
+		}
+		if stmt := parser.parseStatement(); stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
+		parser.nextToken()
+	}
+	return block
+}
+
+func (parser *Parser) parseFunctionLiteral() ast.Expression {
+	fn := &ast.FunctionLiteral{Token: parser.currToken}
+
+	if !parser.expectPeek(token.LPAREN) {
+		return nil
+	}
+	fn.Parameters = parser.parseFunctionParameters()
+	if fn.Parameters == nil {
+		return nil
+	}
+
+	if !parser.expectPeek(token.LBRACE) {
+		return nil
+	}
+	fn.Body = parser.parseBlockStatement()
+	return fn
+}
+
+func (parser *Parser) parseFunctionParameters() []*ast.Identifier {
+	params := []*ast.Identifier{}
+
+	if parser.peekTokenIs(token.RPAREN) {
+		parser.nextToken()
+		return params
+	}
+
+	if !parser.expectPeek(token.IDENT) {
+		return nil
+	}
+	params = append(params, &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal})
+
+	for parser.peekTokenIs(token.COMMA) {
+		parser.nextToken()
+		if !parser.expectPeek(token.IDENT) {
+			return nil
+		}
+		params = append(params, &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal})
+	}
+
+	if !parser.expectPeek(token.RPAREN) {
+		return nil
+	}
+	return params
+}
+
+func (parser *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	expr := &ast.CallExpression{Token: parser.currToken, Function: function}
+	expr.Arguments = parser.parseExpressionList(token.RPAREN)
+	if expr.Arguments == nil {
+		return nil
+	}
+	return expr
+}
+
+// parseExpressionList parses comma-separated expressions up to and
+// including the closing end token.
+func (parser *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
+
+	if parser.peekTokenIs(end) {
+		parser.nextToken()
+		return list
+	}
+
+	parser.nextToken()
+	list = append(list, parser.parseExpression(LOWEST))
+
+	for parser.peekTokenIs(token.COMMA) {
+		parser.nextToken()
+		parser.nextToken()
+		list = append(list, parser.parseExpression(LOWEST))
+	}
+
+	if !parser.expectPeek(end) {
+		return nil
+	}
+	return list
+}
+
+func (parser *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: parser.currToken}
+	array.Elements = parser.parseExpressionList(token.RBRACKET)
+	if array.Elements == nil {
+		return nil
+	}
+	return array
+}
+
+// parseHashLiteral parses `{key: value, ...}`. Blocks only follow `if`,
+// `else` and `fn`, so a `{` in expression position always starts a hash; a
+// missing ':' after the first key is reported with that in mind.
+func (parser *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: parser.currToken, Pairs: []ast.HashPair{}}
+
+	for !parser.peekTokenIs(token.RBRACE) {
+		parser.nextToken()
+		key := parser.parseExpression(LOWEST)
+
+		if !parser.expectPeek(token.COLON) {
+			if len(hash.Pairs) == 0 && !parser.peekTokenIs(token.ILLEGAL) {
+				parser.lastDiagnostic().Hint = "`{` starts a hash literal here; blocks are only allowed after `if`, `else` and `fn`"
+			}
+			return nil
+		}
+
+		parser.nextToken()
+		value := parser.parseExpression(LOWEST)
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !parser.peekTokenIs(token.RBRACE) && !parser.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !parser.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return hash
+}
+
+func (parser *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	expr := &ast.IndexExpression{Token: parser.currToken, Left: left}
+
+	parser.nextToken()
+	expr.Index = parser.parseExpression(LOWEST)
+
+	if !parser.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	return expr
+}
+
 func (parser *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	expr := &ast.InfixExpression{
 		Token:    parser.currToken,
@@ -208,6 +672,24 @@ func (parser *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 		Left:     left,
 	}
 
+	precedence := parser.currPrecedence()
+	if rightAssociative[parser.currToken.Type] {
+		// Parsing the right operand one level lower lets it absorb
+		// further operators of the same precedence.
+		precedence--
+	}
+	parser.nextToken()
+	expr.Right = parser.parseExpression(precedence)
+	return expr
+}
+
+func (parser *Parser) parseLogicalExpression(left ast.Expression) ast.Expression {
+	expr := &ast.LogicalExpression{
+		Token:    parser.currToken,
+		Operator: parser.currToken.Literal,
+		Left:     left,
+	}
+
 	precedence := parser.currPrecedence()
 	parser.nextToken()
 	expr.Right = parser.parseExpression(precedence)
@@ -229,8 +711,13 @@ func (parser *Parser) peekPrecedence() int {
 }
 
 func (parser *Parser) peekError(tok token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s", tok, parser.peekToken.Type)
-	parser.Errors = append(parser.Errors, msg)
+	// the lexer has already reported why the token is illegal
+	if parser.peekTokenIs(token.ILLEGAL) {
+		return
+	}
+	diag := parser.errorAt(parser.peekToken, ErrUnexpectedToken,
+		"expected next token to be %s, got %s", tok, parser.peekToken.Type)
+	diag.Expected = []token.TokenType{tok}
 }
 
 func (parser *Parser) expectPeek(tok token.TokenType) bool {
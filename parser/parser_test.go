@@ -2,14 +2,56 @@ package parser_test
 
 import (
 	"fmt"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/sayandipdutta/monkey/ast"
 	"github.com/sayandipdutta/monkey/lexer"
 	"github.com/sayandipdutta/monkey/parser"
+	"github.com/sayandipdutta/monkey/token"
 )
 
 func TestLetStatement(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedIdentifier string
+		expectedValue      interface{}
+	}{
+		{"let x = 5;", "x", 5},
+		{"let y = z;", "y", "z"},
+		{"let foobar = 123456", "foobar", 123456},
+		{"let ok = true;", "ok", true},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+
+		program := p.ParseProgram()
+		checkParseError(t, p)
+
+		if program == nil {
+			t.Fatal("ParseProgram() returned nil")
+		}
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("expected 1 statement, got (%d)", len(program.Statements))
+		}
+
+		stmt := program.Statements[0]
+		if !testLetStatement(t, stmt, tt.expectedIdentifier) {
+			return
+		}
+
+		value := stmt.(*ast.LetStatment).Value
+		if !testLiteralExpression(t, value, tt.expectedValue) {
+			return
+		}
+	}
+}
+
+func TestLetStatementMultiple(t *testing.T) {
 	input := `
   let x = 5;
   let y = 10;
@@ -21,10 +63,6 @@ func TestLetStatement(t *testing.T) {
 	program := p.ParseProgram()
 	checkParseError(t, p)
 
-	if program == nil {
-		t.Fatal("ParseProgram() returned nil")
-	}
-
 	if len(program.Statements) != 3 {
 		t.Fatalf("expected 3 statements, got (%d)", len(program.Statements))
 	}
@@ -45,7 +83,70 @@ func TestLetStatement(t *testing.T) {
 	}
 }
 
+func TestFailedLetStatementIsDropped(t *testing.T) {
+	l := lexer.New("let = 1; let y = 2; if (true) { let 5; y }")
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if len(p.Diagnostics) == 0 {
+		t.Fatalf("expected diagnostics, got none")
+	}
+	for i, stmt := range program.Statements {
+		if stmt == nil {
+			t.Fatalf("program.Statements[%d] is nil", i)
+		}
+	}
+	if program.String() == "" {
+		t.Fatalf("program.String() is empty")
+	}
+}
+
 func TestReturnStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedValue interface{}
+	}{
+		{"return 5;", 5},
+		{"return 10", 10},
+		{"return foobar;", "foobar"},
+		{"return;", nil},
+		{"return", nil},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+
+		program := p.ParseProgram()
+		checkParseError(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("expected 1 statement, got (%d)", len(program.Statements))
+		}
+
+		retstmt, ok := program.Statements[0].(*ast.ReturnStatement)
+		if !ok {
+			t.Fatalf("Not a Return Statement. got=%T", program.Statements[0])
+		}
+
+		if retstmt.TokenLiteral() != "return" {
+			t.Fatalf("Wrong literal. Expected=%s, got=%s", "return", retstmt.TokenLiteral())
+		}
+
+		if tt.expectedValue == nil {
+			if retstmt.Value != nil {
+				t.Fatalf("expected no return value, got=%s", retstmt.Value.String())
+			}
+			continue
+		}
+
+		if !testLiteralExpression(t, retstmt.Value, tt.expectedValue) {
+			return
+		}
+	}
+}
+
+func TestReturnStatementMultiple(t *testing.T) {
 	input := `
   return 5;
   return 10;
@@ -57,24 +158,9 @@ func TestReturnStatement(t *testing.T) {
 	program := p.ParseProgram()
 	checkParseError(t, p)
 
-	if program == nil {
-		t.Fatal("ParseProgram() returned nil")
-	}
-
 	if len(program.Statements) != 3 {
 		t.Fatalf("expected 3 statements, got (%d)", len(program.Statements))
 	}
-
-	for _, stmt := range program.Statements {
-		retstmt, ok := stmt.(*ast.ReturnStatement)
-		if !ok {
-			t.Fatalf("Not a Return Statement. got=%T", retstmt)
-		}
-
-		if retstmt.TokenLiteral() != "return" {
-			t.Fatalf("Wrong literal. Expected=%s, got=%s", "return", retstmt.TokenLiteral())
-		}
-	}
 }
 
 func testLetStatement(t *testing.T, stmt ast.Statement, expected string) bool {
@@ -103,7 +189,7 @@ func testLetStatement(t *testing.T, stmt ast.Statement, expected string) bool {
 }
 
 func checkParseError(t *testing.T, p *parser.Parser) {
-	errors := p.Errors
+	errors := p.Errors()
 
 	if len(errors) == 0 {
 		return
@@ -178,6 +264,202 @@ func TestIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerBases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0Xff", 255},
+		{"0o755", 493},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0b_1111_0000", 240},
+		{"007", 7},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParseError(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("expression not *ast.IntegerLiteral, got=%T", stmt.Expression)
+		}
+
+		if literal.Value != tt.expected {
+			t.Fatalf("Value expected=%d, got=%d", tt.expected, literal.Value)
+		}
+
+		if literal.String() != tt.input {
+			t.Fatalf("String expected=%s, got=%s", tt.input, literal.String())
+		}
+	}
+}
+
+func TestMalformedIntegerLiteral(t *testing.T) {
+	l := lexer.New("let x = 0xZZ;")
+	p := parser.New(l)
+	p.ParseProgram()
+
+	if len(p.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got=%d: %v", len(p.Diagnostics), p.Errors())
+	}
+
+	expected := "1:11: invalid digit 'Z' in hexadecimal literal"
+	if p.Diagnostics[0].Error() != expected {
+		t.Fatalf("wrong error. expected=%q, got=%q", expected, p.Diagnostics[0].Error())
+	}
+}
+
+func TestIntegerOverflow(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"9223372036854775808",
+			"1:1: integer literal 9223372036854775808 overflows int64; valid range is -9223372036854775808 to 9223372036854775807",
+		},
+		{
+			"x + 0xFFFF_FFFF_FFFF_FFFF",
+			"1:5: integer literal 0xFFFF_FFFF_FFFF_FFFF overflows int64; valid range is -9223372036854775808 to 9223372036854775807",
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		p.ParseProgram()
+
+		if len(p.Diagnostics) != 1 {
+			t.Fatalf("expected 1 diagnostic, got=%d: %v", len(p.Diagnostics), p.Errors())
+		}
+
+		diag := p.Diagnostics[0]
+		if diag.Code != parser.ErrIntegerOverflow {
+			t.Fatalf("wrong code. expected=%s, got=%s", parser.ErrIntegerOverflow, diag.Code)
+		}
+		if diag.Error() != tt.expected {
+			t.Fatalf("wrong error. expected=%q, got=%q", tt.expected, diag.Error())
+		}
+	}
+}
+
+func TestMinInt64Literal(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"-9223372036854775808", "-9223372036854775808"},
+		{"-0x8000_0000_0000_0000 + 1", "(-0x8000_0000_0000_0000 + 1)"},
+		{"x - 9223372036854775807", "(x - 9223372036854775807)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParseError(t, p)
+
+		if program.String() != tt.expected {
+			t.Fatalf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	l := lexer.New("x; -9223372036854775808")
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	stmt := program.Statements[1].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+	if !ok {
+		t.Fatalf("expression not *ast.IntegerLiteral, got=%T", stmt.Expression)
+	}
+	if literal.Value != math.MinInt64 {
+		t.Fatalf("Value expected=%d, got=%d", int64(math.MinInt64), literal.Value)
+	}
+	if literal.TokenLiteral() != "-9223372036854775808" || literal.Pos().Offset != 3 {
+		t.Fatalf("wrong token. got=%q at %d", literal.TokenLiteral(), literal.Pos().Offset)
+	}
+
+	for _, input := range []string{"x - 9223372036854775808", "-9223372036854775808[0]"} {
+		l := lexer.New(input)
+		p := parser.New(l)
+		p.ParseProgram()
+		if len(p.Diagnostics) != 1 || p.Diagnostics[0].Code != parser.ErrIntegerOverflow {
+			t.Fatalf("expected an overflow for %q, got=%v", input, p.Errors())
+		}
+	}
+}
+
+func TestBigIntegerMode(t *testing.T) {
+	l := lexer.New("9223372036854775807 + 0x1_0000_0000_0000_0000")
+	p := parser.NewWithMode(l, parser.BigIntegers)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	infix, ok := stmt.Expression.(*ast.InfixExpression)
+	if !ok {
+		t.Fatalf("expression not *ast.InfixExpression, got=%T", stmt.Expression)
+	}
+
+	if !testIntegerLiteral(t, infix.Left, 9223372036854775807) {
+		return
+	}
+
+	big, ok := infix.Right.(*ast.BigIntegerLiteral)
+	if !ok {
+		t.Fatalf("right operand not *ast.BigIntegerLiteral, got=%T", infix.Right)
+	}
+
+	if big.Value.String() != "18446744073709551616" {
+		t.Fatalf("Value expected=18446744073709551616, got=%s", big.Value)
+	}
+
+	if big.String() != "0x1_0000_0000_0000_0000" {
+		t.Fatalf("String expected=0x1_0000_0000_0000_0000, got=%s", big.String())
+	}
+}
+
+func TestFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14;", 3.14},
+		{".5", 0.5},
+		{"1e-9", 1e-9},
+		{"1_000.5", 1000.5},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParseError(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("expression not *ast.FloatLiteral, got=%T", stmt.Expression)
+		}
+
+		if literal.Value != tt.expected {
+			t.Fatalf("Value expected=%g, got=%g", tt.expected, literal.Value)
+		}
+
+		if literal.String() != strings.TrimSuffix(tt.input, ";") {
+			t.Fatalf("String expected=%s, got=%s", tt.input, literal.String())
+		}
+	}
+}
+
 func TestPrefixExpression(t *testing.T) {
 	prefixTests := []struct {
 		input        string
@@ -185,6 +467,7 @@ func TestPrefixExpression(t *testing.T) {
 		integerValue int64
 	}{
 		{"!5;", "!", 5},
+		{"~5;", "~", 5},
 		{"-21;", "-", 1},
 	}
 
@@ -238,8 +521,8 @@ func TestInfixExpressions(t *testing.T) {
 	infixTests := []struct {
 		input    string
 		operator string
-		left     int64
-		right    int64
+		left     interface{}
+		right    interface{}
 	}{
 		{"5 + 5;", "+", 5, 5},
 		{"5 - 5;", "-", 5, 5},
@@ -249,6 +532,18 @@ func TestInfixExpressions(t *testing.T) {
 		{"5 < 5;", "<", 5, 5},
 		{"5 == 5;", "==", 5, 5},
 		{"5 != 5;", "!=", 5, 5},
+		{"5 <= 5;", "<=", 5, 5},
+		{"5 >= 5;", ">=", 5, 5},
+		{"5 << 5;", "<<", 5, 5},
+		{"5 >> 5;", ">>", 5, 5},
+		{"5 % 5;", "%", 5, 5},
+		{"5 & 5;", "&", 5, 5},
+		{"5 | 5;", "|", 5, 5},
+		{"5 ^ 5;", "^", 5, 5},
+		{"5 ** 5;", "**", 5, 5},
+		{"true == true", "==", true, true},
+		{"true != false", "!=", true, false},
+		{"false == false", "==", false, false},
 	}
 
 	for _, tt := range infixTests {
@@ -266,21 +561,8 @@ func TestInfixExpressions(t *testing.T) {
 			t.Fatalf("Expected ExpressionStatement, found=%T", program.Statements[0])
 		}
 
-		expr, ok := stmt.Expression.(*ast.InfixExpression)
-		if !ok {
-			t.Fatalf("Expected InfixExpression, found=%T", stmt.Expression)
-		}
-
-		if expr.Operator != tt.operator {
-			t.Fatalf("Expected operator to be=%s, found=%s", tt.operator, expr.Operator)
-		}
-
-		if !testIntegerLiteral(t, expr.Left, tt.left) {
-			t.Fatalf("Expected left operand=%d, found=%d", tt.left, expr.Left)
-		}
-
-		if !testIntegerLiteral(t, expr.Right, tt.right) {
-			t.Fatalf("Expected right operand=%d, found=%d", tt.right, expr.Right)
+		if !testInfixExpression(t, stmt.Expression, tt.left, tt.operator, tt.right) {
+			return
 		}
 	}
 }
@@ -322,75 +604,909 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"3 + 4 * 5 != 3 * 1 - 4 / 5",
 			"((3 + (4 * 5)) != ((3 * 1) - (4 / 5)))",
 		},
-	}
-
-	for _, tt := range tests {
-		lexer := lexer.New(tt.input)
-		parser := parser.New(lexer)
-		program := parser.ParseProgram()
-
-		checkParseError(t, parser)
-
-		if program.String() != tt.expected {
-			t.Fatalf("Expected string=%s, found=%s", tt.expected, program.String())
-		}
-	}
-}
-
-func testIdentifier(t *testing.T, expr ast.Expression, value string) bool {
-	ident, ok := expr.(*ast.Identifier)
-	if !ok {
-		t.Errorf("intlit not *ast.IntegerLiteral. got=%T", expr)
-		return false
-	}
-
-	if ident.Value != value {
-		t.Errorf("Expected integer=%s, got=%s", value, ident.Value)
-		return false
-	}
-
-	if ident.TokenLiteral() != value {
-		t.Errorf("Expected integer=%s, got=%s", value, ident.TokenLiteral())
-		return false
-	}
-
-	return true
-}
-
-func testLiteralExpression(t *testing.T, expr ast.Expression, expected interface{}) bool {
-	switch v := expected.(type) {
-	case int:
-		return testIntegerLiteral(t, expr, int64(v))
-	case int64:
-		return testIntegerLiteral(t, expr, v)
-	case string:
-		return testIdentifier(t, expr, v)
-	default:
-		t.Errorf("type of expression not handled. got=%T", expr)
-		return false
-	}
-}
-
-func testInfixExpression(t *testing.T, expr ast.Expression, left interface{},
-	operator string, right interface{},
-) bool {
-	infExpr, ok := expr.(*ast.InfixExpression)
-	if !ok {
-		t.Errorf("expr is not ast.InfixExpression. got %T(%s)", expr, expr.String())
-		return false
-	}
-
-	if !testLiteralExpression(t, infExpr.Left, left) {
-		return false
-	}
-
-	if infExpr.Operator != operator {
-		t.Errorf("expected=%s, got=%q", operator, infExpr.Operator)
-	}
-
-	if !testLiteralExpression(t, infExpr.Right, right) {
-		return false
-	}
-
-	return true
+		{
+			"a <= b == c >= d",
+			"((a <= b) == (c >= d))",
+		},
+		{
+			"a << 1 + 2",
+			"((a << 1) + 2)",
+		},
+		{
+			"a + 1 >> b * 2",
+			"(a + ((1 >> b) * 2))",
+		},
+		{
+			"a < b << c",
+			"(a < (b << c))",
+		},
+		{
+			"a >> b <= c << d",
+			"((a >> b) <= (c << d))",
+		},
+		{
+			"a << b >> c",
+			"((a << b) >> c)",
+		},
+		{
+			"a || b && c == d",
+			"(a || (b && (c == d)))",
+		},
+		{
+			"a && b || c && d",
+			"((a && b) || (c && d))",
+		},
+		{
+			"!a && b < c",
+			"((!a) && (b < c))",
+		},
+		{
+			"a || b || c",
+			"((a || b) || c)",
+		},
+		{
+			"a | b & c",
+			"(a | (b & c))",
+		},
+		{
+			"a ^ b * c % d",
+			"(a ^ ((b * c) % d))",
+		},
+		{
+			"a & b == c",
+			"((a & b) == c)",
+		},
+		{
+			"a & b && c | d",
+			"((a & b) && (c | d))",
+		},
+		{
+			"~a & -b",
+			"((~a) & (-b))",
+		},
+		{
+			"a << b | c",
+			"((a << b) | c)",
+		},
+		{
+			"2 ** 3 ** 2",
+			"(2 ** (3 ** 2))",
+		},
+		{
+			"-2 ** 2",
+			"(-(2 ** 2))",
+		},
+		{
+			"2 ** -2",
+			"(2 ** (-2))",
+		},
+		{
+			"a * b ** c * d",
+			"((a * (b ** c)) * d)",
+		},
+		{
+			"a ** b[0] ** f(c)",
+			"(a ** ((b[0]) ** f(c)))",
+		},
+		{
+			"a - b - c",
+			"((a - b) - c)",
+		},
+		{
+			"true",
+			"true",
+		},
+		{
+			"3 > 5 == false",
+			"((3 > 5) == false)",
+		},
+		{
+			"!true == false",
+			"((!true) == false)",
+		},
+		{
+			"1 + (2 + 3) + 4",
+			"((1 + (2 + 3)) + 4)",
+		},
+		{
+			"(5 + 5) * 2",
+			"((5 + 5) * 2)",
+		},
+		{
+			"2 / (5 + 5)",
+			"(2 / (5 + 5))",
+		},
+		{
+			"-(5 + 5)",
+			"(-(5 + 5))",
+		},
+		{
+			"!(true == true)",
+			"(!(true == true))",
+		},
+		{
+			"((1 + 2) * (3 - (4 + 5)))",
+			"((1 + 2) * (3 - (4 + 5)))",
+		},
+		{
+			"(((a)))",
+			"a",
+		},
+		{
+			"a + add(b * c) + d",
+			"((a + add((b * c))) + d)",
+		},
+		{
+			"add(a, b, 1, 2 * 3, 4 + 5, add(6, 7 * 8))",
+			"add(a, b, 1, (2 * 3), (4 + 5), add(6, (7 * 8)))",
+		},
+		{
+			"add(a + b + c * d / f + g)",
+			"add((((a + b) + ((c * d) / f)) + g))",
+		},
+		{
+			"fn(x) { x }(5)",
+			"fn(x) x(5)",
+		},
+		{
+			"1.5 * x + .5",
+			"((1.5 * x) + .5)",
+		},
+		{
+			"a * [1, 2, 3, 4][b * c] * d",
+			"((a * ([1, 2, 3, 4][(b * c)])) * d)",
+		},
+		{
+			"add(a * b[2], b[1], 2 * [1, 2][1])",
+			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
+		},
+		{
+			"fns[0](x)",
+			"(fns[0])(x)",
+		},
+		{
+			"x += a * b",
+			"x += (a * b);",
+		},
+		{
+			"let sum = a + b * c",
+			"let sum = (a + (b * c));",
+		},
+		{
+			"return a * b",
+			"return (a * b);",
+		},
+		{
+			"return;",
+			"return;",
+		},
+	}
+
+	for _, tt := range tests {
+		lexer := lexer.New(tt.input)
+		parser := parser.New(lexer)
+		program := parser.ParseProgram()
+
+		checkParseError(t, parser)
+
+		if program.String() != tt.expected {
+			t.Fatalf("Expected string=%s, found=%s", tt.expected, program.String())
+		}
+	}
+}
+
+func testIdentifier(t *testing.T, expr ast.Expression, value string) bool {
+	ident, ok := expr.(*ast.Identifier)
+	if !ok {
+		t.Errorf("intlit not *ast.IntegerLiteral. got=%T", expr)
+		return false
+	}
+
+	if ident.Value != value {
+		t.Errorf("Expected integer=%s, got=%s", value, ident.Value)
+		return false
+	}
+
+	if ident.TokenLiteral() != value {
+		t.Errorf("Expected integer=%s, got=%s", value, ident.TokenLiteral())
+		return false
+	}
+
+	return true
+}
+
+func testBooleanLiteral(t *testing.T, expr ast.Expression, value bool) bool {
+	boolean, ok := expr.(*ast.Boolean)
+	if !ok {
+		t.Errorf("expr not *ast.Boolean. got=%T", expr)
+		return false
+	}
+
+	if boolean.Value != value {
+		t.Errorf("Expected boolean=%t, got=%t", value, boolean.Value)
+		return false
+	}
+
+	if boolean.TokenLiteral() != fmt.Sprintf("%t", value) {
+		t.Errorf("Expected boolean=%t, got=%s", value, boolean.TokenLiteral())
+		return false
+	}
+
+	return true
+}
+
+func testLiteralExpression(t *testing.T, expr ast.Expression, expected interface{}) bool {
+	switch v := expected.(type) {
+	case int:
+		return testIntegerLiteral(t, expr, int64(v))
+	case int64:
+		return testIntegerLiteral(t, expr, v)
+	case string:
+		return testIdentifier(t, expr, v)
+	case bool:
+		return testBooleanLiteral(t, expr, v)
+	default:
+		t.Errorf("type of expression not handled. got=%T", expr)
+		return false
+	}
+}
+
+func testInfixExpression(t *testing.T, expr ast.Expression, left interface{},
+	operator string, right interface{},
+) bool {
+	infExpr, ok := expr.(*ast.InfixExpression)
+	if !ok {
+		t.Errorf("expr is not ast.InfixExpression. got %T(%s)", expr, expr.String())
+		return false
+	}
+
+	if !testLiteralExpression(t, infExpr.Left, left) {
+		return false
+	}
+
+	if infExpr.Operator != operator {
+		t.Errorf("expected=%s, got=%q", operator, infExpr.Operator)
+	}
+
+	if !testLiteralExpression(t, infExpr.Right, right) {
+		return false
+	}
+
+	return true
+}
+
+func TestErrorPositions(t *testing.T) {
+	input := "let x = 5;\nlet 10;"
+
+	l := lexer.NewFile("main.mk", input)
+	p := parser.New(l)
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+
+	expected := "main.mk:2:5: expected next token to be IDENT, got INT"
+	if p.Errors()[0] != expected {
+		t.Fatalf("wrong error. expected=%q, got=%q", expected, p.Errors()[0])
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	input := "let x 5;"
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	p.ParseProgram()
+
+	if len(p.Diagnostics) == 0 {
+		t.Fatalf("expected diagnostics, got none")
+	}
+
+	diag := p.Diagnostics[0]
+	if diag.Severity != parser.SeverityError {
+		t.Errorf("wrong severity. expected=%s, got=%s", parser.SeverityError, diag.Severity)
+	}
+	if diag.Code != parser.ErrUnexpectedToken {
+		t.Errorf("wrong code. expected=%s, got=%s", parser.ErrUnexpectedToken, diag.Code)
+	}
+	if len(diag.Expected) != 1 || diag.Expected[0] != token.ASSIGN {
+		t.Errorf("wrong expected tokens. expected=[%s], got=%v", token.ASSIGN, diag.Expected)
+	}
+	if diag.Actual != token.INT {
+		t.Errorf("wrong actual token. expected=%s, got=%s", token.INT, diag.Actual)
+	}
+	if diag.Span.Start.Offset != 6 || diag.Span.End.Offset != 7 {
+		t.Errorf("wrong span. expected=[6, 7), got=[%d, %d)",
+			diag.Span.Start.Offset, diag.Span.End.Offset)
+	}
+
+	var err error = diag
+	if err.Error() != "1:7: expected next token to be =, got INT" {
+		t.Errorf("wrong error message. got=%q", err.Error())
+	}
+}
+
+func TestUnclosedGroupedExpression(t *testing.T) {
+	l := lexer.New("(1 + 2")
+	p := parser.New(l)
+	p.ParseProgram()
+
+	if len(p.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got=%d", len(p.Diagnostics))
+	}
+
+	diag := p.Diagnostics[0]
+	if len(diag.Expected) != 1 || diag.Expected[0] != token.RPAREN {
+		t.Fatalf("wrong expected tokens. expected=[%s], got=%v", token.RPAREN, diag.Expected)
+	}
+}
+
+func TestIfExpression(t *testing.T) {
+	input := `if (x < y) { x }`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("Expected 1 statement, found=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Expected ExpressionStatement, found=%T", program.Statements[0])
+	}
+
+	expr, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("Expected IfExpression, found=%T", stmt.Expression)
+	}
+
+	if !testInfixExpression(t, expr.Condition, "x", "<", "y") {
+		return
+	}
+
+	if len(expr.Consequence.Statements) != 1 {
+		t.Fatalf("Expected 1 consequence statement, found=%d", len(expr.Consequence.Statements))
+	}
+
+	consequence, ok := expr.Consequence.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Expected ExpressionStatement, found=%T", expr.Consequence.Statements[0])
+	}
+
+	if !testIdentifier(t, consequence.Expression, "x") {
+		return
+	}
+
+	if expr.Alternative != nil {
+		t.Fatalf("Expected no alternative, found=%s", expr.Alternative.String())
+	}
+}
+
+func TestIfElseExpression(t *testing.T) {
+	input := `if (x < y) { x } else { return y; }`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	expr, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("Expected IfExpression, found=%T", stmt.Expression)
+	}
+
+	if expr.Alternative == nil || len(expr.Alternative.Statements) != 1 {
+		t.Fatalf("Expected 1 alternative statement, found=%v", expr.Alternative)
+	}
+
+	alternative, ok := expr.Alternative.Statements[0].(*ast.ReturnStatement)
+	if !ok {
+		t.Fatalf("Expected ReturnStatement, found=%T", expr.Alternative.Statements[0])
+	}
+
+	if !testIdentifier(t, alternative.Value, "y") {
+		return
+	}
+}
+
+func TestElseIfExpression(t *testing.T) {
+	input := `if (x < y) { x } else if (x > y) { y } else { 0 }`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	expr := stmt.Expression.(*ast.IfExpression)
+
+	if expr.Alternative == nil || len(expr.Alternative.Statements) != 1 {
+		t.Fatalf("Expected 1 alternative statement, found=%v", expr.Alternative)
+	}
+
+	nested, ok := expr.Alternative.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Expected ExpressionStatement, found=%T", expr.Alternative.Statements[0])
+	}
+
+	elseIf, ok := nested.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("Expected nested IfExpression, found=%T", nested.Expression)
+	}
+
+	if !testInfixExpression(t, elseIf.Condition, "x", ">", "y") {
+		return
+	}
+
+	if elseIf.Alternative == nil {
+		t.Fatalf("Expected final else block")
+	}
+
+	expected := "if(x < y) x else if(x > y) y else 0"
+	if program.String() != expected {
+		t.Fatalf("Expected string=%s, found=%s", expected, program.String())
+	}
+}
+
+func TestUnclosedBlock(t *testing.T) {
+	l := lexer.New("if (x) { x")
+	p := parser.New(l)
+	p.ParseProgram()
+
+	if len(p.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got=%d: %v", len(p.Diagnostics), p.Errors())
+	}
+
+	diag := p.Diagnostics[0]
+	if len(diag.Expected) != 1 || diag.Expected[0] != token.RBRACE {
+		t.Fatalf("wrong expected tokens. expected=[%s], got=%v", token.RBRACE, diag.Expected)
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `fn(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("Expected 1 statement, found=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Expected ExpressionStatement, found=%T", program.Statements[0])
+	}
+
+	fn, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("Expected FunctionLiteral, found=%T", stmt.Expression)
+	}
+
+	if len(fn.Parameters) != 2 {
+		t.Fatalf("Expected 2 parameters, found=%d", len(fn.Parameters))
+	}
+
+	testLiteralExpression(t, fn.Parameters[0], "x")
+	testLiteralExpression(t, fn.Parameters[1], "y")
+
+	if len(fn.Body.Statements) != 1 {
+		t.Fatalf("Expected 1 body statement, found=%d", len(fn.Body.Statements))
+	}
+
+	body, ok := fn.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Expected ExpressionStatement, found=%T", fn.Body.Statements[0])
+	}
+
+	testInfixExpression(t, body.Expression, "x", "+", "y")
+}
+
+func TestFunctionParameterParsing(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+	}{
+		{"fn() {};", []string{}},
+		{"fn(x) {};", []string{"x"}},
+		{"fn(x, y, z) {};", []string{"x", "y", "z"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParseError(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		fn := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(fn.Parameters) != len(tt.expectedParams) {
+			t.Fatalf("Expected %d parameters, found=%d", len(tt.expectedParams), len(fn.Parameters))
+		}
+
+		for i, ident := range tt.expectedParams {
+			testLiteralExpression(t, fn.Parameters[i], ident)
+		}
+	}
+}
+
+func TestCallExpressionParsing(t *testing.T) {
+	input := "add(1, 2 * 3, 4 + 5);"
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("Expected 1 statement, found=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Expected ExpressionStatement, found=%T", program.Statements[0])
+	}
+
+	expr, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("Expected CallExpression, found=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, expr.Function, "add") {
+		return
+	}
+
+	if len(expr.Arguments) != 3 {
+		t.Fatalf("Expected 3 arguments, found=%d", len(expr.Arguments))
+	}
+
+	testLiteralExpression(t, expr.Arguments[0], 1)
+	testInfixExpression(t, expr.Arguments[1], 2, "*", 3)
+	testInfixExpression(t, expr.Arguments[2], 4, "+", 5)
+}
+
+func TestAssignStatement(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedTarget   string
+		expectedOperator string
+		expectedValue    interface{}
+	}{
+		{"x = 5;", "x", "=", 5},
+		{"x += 1;", "x", "+=", 1},
+		{"y -= z", "y", "-=", "z"},
+		{"total *= 2;", "total", "*=", 2},
+		{"total /= 2;", "total", "/=", 2},
+		{"ok = true", "ok", "=", true},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParseError(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("Expected 1 statement, found=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.AssignStatement)
+		if !ok {
+			t.Fatalf("Expected AssignStatement, found=%T", program.Statements[0])
+		}
+
+		if !testIdentifier(t, stmt.Target, tt.expectedTarget) {
+			return
+		}
+
+		if stmt.Operator != tt.expectedOperator {
+			t.Fatalf("Expected operator=%s, found=%s", tt.expectedOperator, stmt.Operator)
+		}
+
+		if !testLiteralExpression(t, stmt.Value, tt.expectedValue) {
+			return
+		}
+	}
+}
+
+func TestInvalidAssignTarget(t *testing.T) {
+	l := lexer.New("a + b = 5;")
+	p := parser.New(l)
+	p.ParseProgram()
+
+	if len(p.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got=%d: %v", len(p.Diagnostics), p.Errors())
+	}
+
+	diag := p.Diagnostics[0]
+	if diag.Code != parser.ErrInvalidAssignTarget {
+		t.Fatalf("wrong code. expected=%s, got=%s", parser.ErrInvalidAssignTarget, diag.Code)
+	}
+
+	expected := "1:7: cannot assign to `(a + b)`"
+	if diag.Error() != expected {
+		t.Fatalf("wrong error. expected=%q, got=%q", expected, diag.Error())
+	}
+}
+
+func TestAssignToInvalidToken(t *testing.T) {
+	l := lexer.New("0xZZ = 5;")
+	p := parser.New(l)
+	p.ParseProgram()
+
+	if len(p.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got=%d: %v", len(p.Diagnostics), p.Errors())
+	}
+	if p.Diagnostics[0].Code != parser.ErrInvalidToken {
+		t.Fatalf("wrong code. expected=%s, got=%s", parser.ErrInvalidToken, p.Diagnostics[0].Code)
+	}
+}
+
+func TestIllegalTokenReportedOnce(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let \xff = 1;", "1:5: invalid UTF-8 encoding"},
+		{"let x # 1;", "1:7: unexpected character '#'"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		p.ParseProgram()
+
+		if len(p.Diagnostics) != 1 {
+			t.Fatalf("expected 1 diagnostic for %q, got=%d: %v", tt.input, len(p.Diagnostics), p.Errors())
+		}
+		if p.Diagnostics[0].Error() != tt.expected {
+			t.Fatalf("wrong error. expected=%q, got=%q", tt.expected, p.Diagnostics[0].Error())
+		}
+	}
+}
+
+func TestStringLiteralExpression(t *testing.T) {
+	input := `"hello\tworld";`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.StringLiteral)
+	if !ok {
+		t.Fatalf("Expected StringLiteral, found=%T", stmt.Expression)
+	}
+
+	if literal.Value != "hello\tworld" {
+		t.Fatalf("Expected value=%q, found=%q", "hello\tworld", literal.Value)
+	}
+
+	if literal.TokenLiteral() != `"hello\tworld"` {
+		t.Fatalf("Expected token literal=%q, found=%q", `"hello\tworld"`, literal.TokenLiteral())
+	}
+}
+
+func TestUnterminatedString(t *testing.T) {
+	l := lexer.New(`let s = "abc;`)
+	p := parser.New(l)
+	p.ParseProgram()
+
+	if len(p.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got=%d: %v", len(p.Diagnostics), p.Errors())
+	}
+
+	diag := p.Diagnostics[0]
+	if diag.Code != parser.ErrInvalidToken {
+		t.Fatalf("wrong code. expected=%s, got=%s", parser.ErrInvalidToken, diag.Code)
+	}
+
+	if diag.Error() != "1:9: unterminated string literal" {
+		t.Fatalf("wrong error. got=%q", diag.Error())
+	}
+}
+
+func TestMultilineTokenSpan(t *testing.T) {
+	l := lexer.New("let \"ab\ncd\" = 1;")
+	p := parser.New(l)
+	p.ParseProgram()
+
+	if len(p.Diagnostics) == 0 {
+		t.Fatalf("expected diagnostics, got none")
+	}
+
+	span := p.Diagnostics[0].Span
+	if span.Start.String() != "1:5" || span.End.String() != "2:4" {
+		t.Fatalf("wrong span. expected=1:5-2:4, got=%s-%s", span.Start, span.End)
+	}
+	if span.End.Offset != 11 {
+		t.Fatalf("wrong end offset. expected=11, got=%d", span.End.Offset)
+	}
+}
+
+func TestInvalidEscape(t *testing.T) {
+	l := lexer.New(`let s = "a\qb";`)
+	p := parser.New(l)
+	p.ParseProgram()
+
+	if len(p.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got=%d: %v", len(p.Diagnostics), p.Errors())
+	}
+
+	if err := p.Diagnostics[0].Error(); err != "1:11: unknown escape sequence \\q" {
+		t.Fatalf("wrong error. got=%q", err)
+	}
+}
+
+func TestCommentsAreSkipped(t *testing.T) {
+	input := `
+// add two numbers
+let add = fn(a, b) { /* sum */ a + b };
+add(1, 2) // three
+`
+	l := lexer.NewFileWithMode("", input, lexer.ScanComments)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	expected := "let add = fn(a, b) (a + b);add(1, 2)"
+	if program.String() != expected {
+		t.Fatalf("Expected string=%s, found=%s", expected, program.String())
+	}
+}
+
+func TestArrayLiteralParsing(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("Expected ArrayLiteral, found=%T", stmt.Expression)
+	}
+
+	if len(array.Elements) != 3 {
+		t.Fatalf("Expected 3 elements, found=%d", len(array.Elements))
+	}
+
+	testIntegerLiteral(t, array.Elements[0], 1)
+	testInfixExpression(t, array.Elements[1], 2, "*", 2)
+	testInfixExpression(t, array.Elements[2], 3, "+", 3)
+}
+
+func TestEmptyArrayLiteralParsing(t *testing.T) {
+	l := lexer.New("[]")
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("Expected ArrayLiteral, found=%T", stmt.Expression)
+	}
+
+	if len(array.Elements) != 0 {
+		t.Fatalf("Expected 0 elements, found=%d", len(array.Elements))
+	}
+}
+
+func TestIndexExpressionParsing(t *testing.T) {
+	input := "myArray[1 + 1]"
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	expr, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("Expected IndexExpression, found=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, expr.Left, "myArray") {
+		return
+	}
+
+	if !testInfixExpression(t, expr.Index, 1, "+", 1) {
+		return
+	}
+}
+
+func TestIndexAssignStatement(t *testing.T) {
+	l := lexer.New("xs[0] += 1;")
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.AssignStatement)
+	if !ok {
+		t.Fatalf("Expected AssignStatement, found=%T", program.Statements[0])
+	}
+
+	if _, ok := stmt.Target.(*ast.IndexExpression); !ok {
+		t.Fatalf("Expected IndexExpression target, found=%T", stmt.Target)
+	}
+
+	if stmt.String() != "(xs[0]) += 1;" {
+		t.Fatalf("Expected string=%s, found=%s", "(xs[0]) += 1;", stmt.String())
+	}
+}
+
+func TestHashLiteralParsing(t *testing.T) {
+	input := `{"one": 1, "two": 2 * 1, 3: true, false: x,}`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("Expected HashLiteral, found=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 4 {
+		t.Fatalf("Expected 4 pairs, found=%d", len(hash.Pairs))
+	}
+
+	expected := `{"one": 1, "two": (2 * 1), 3: true, false: x}`
+	if hash.String() != expected {
+		t.Fatalf("Expected string=%s, found=%s", expected, hash.String())
+	}
+
+	testIntegerLiteral(t, hash.Pairs[0].Value, 1)
+	testInfixExpression(t, hash.Pairs[1].Value, 2, "*", 1)
+	testIntegerLiteral(t, hash.Pairs[2].Key, 3)
+	testBooleanLiteral(t, hash.Pairs[3].Key, false)
+}
+
+func TestEmptyHashLiteralParsing(t *testing.T) {
+	l := lexer.New("let h = {};")
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParseError(t, p)
+
+	hash, ok := program.Statements[0].(*ast.LetStatment).Value.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("Expected HashLiteral, found=%T", program.Statements[0].(*ast.LetStatment).Value)
+	}
+
+	if len(hash.Pairs) != 0 {
+		t.Fatalf("Expected 0 pairs, found=%d", len(hash.Pairs))
+	}
+}
+
+func TestBlockWhereHashExpected(t *testing.T) {
+	l := lexer.New("{ x + 1 }")
+	p := parser.New(l)
+	p.ParseProgram()
+
+	if len(p.Diagnostics) == 0 {
+		t.Fatalf("expected diagnostics, got none")
+	}
+
+	diag := p.Diagnostics[0]
+	if len(diag.Expected) != 1 || diag.Expected[0] != token.COLON {
+		t.Fatalf("wrong expected tokens. expected=[%s], got=%v", token.COLON, diag.Expected)
+	}
+
+	if diag.Hint == "" {
+		t.Fatalf("expected a hint explaining hash literals")
+	}
 }
@@ -6,14 +6,19 @@ import (
 	"io"
 	"os"
 
+	"github.com/sayandipdutta/monkey/ast"
+	"github.com/sayandipdutta/monkey/evaluator"
 	"github.com/sayandipdutta/monkey/lexer"
+	"github.com/sayandipdutta/monkey/object"
 	"github.com/sayandipdutta/monkey/parser"
+	"github.com/sayandipdutta/monkey/report"
 )
 
 const PROMPT = ">> "
 
 func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
+	env := object.NewEnvironment()
 
 	for {
 		fmt.Print(PROMPT)
@@ -30,6 +35,28 @@ func Start(in io.Reader, out io.Writer) {
 		lexer := lexer.New(line)
 		parser := parser.New(lexer)
 		program := parser.ParseProgram()
-		fmt.Println(program.String())
+		if len(parser.Diagnostics) != 0 {
+			report.Render(out, line, parser.Diagnostics, report.Plain)
+			continue
+		}
+		evaluated := evaluator.Eval(program, env)
+		if evaluated == nil || evaluated == evaluator.NULL && endsWithBinding(program) {
+			continue
+		}
+		io.WriteString(out, evaluated.Inspect()+"\n")
+	}
+}
+
+// endsWithBinding reports whether the last statement of program is a let or
+// an assignment. Those evaluate to null, which is not worth printing.
+func endsWithBinding(program *ast.Program) bool {
+	if len(program.Statements) == 0 {
+		return false
+	}
+	switch program.Statements[len(program.Statements)-1].(type) {
+	case *ast.LetStatment, *ast.AssignStatement:
+		return true
+	default:
+		return false
 	}
 }
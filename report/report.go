@@ -0,0 +1,170 @@
+// Package report renders parser diagnostics for humans and tools.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sayandipdutta/monkey/parser"
+	"github.com/sayandipdutta/monkey/token"
+)
+
+type Mode int
+
+const (
+	Plain Mode = iota // rustc-style text
+	Color             // rustc-style text with ANSI colors
+	JSON              // one JSON array of diagnostics
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[1;31m"
+	ansiBlue  = "\x1b[1;34m"
+	ansiCyan  = "\x1b[1;36m"
+)
+
+const byteOrderMark = "\uFEFF"
+
+// Render writes diags to w in the given mode. source must be the input the
+// diagnostics were produced from; it is used to print the offending lines.
+func Render(w io.Writer, source string, diags []*parser.Diagnostic, mode Mode) error {
+	if mode == JSON {
+		return renderJSON(w, diags)
+	}
+
+	r := &renderer{source: source, color: mode == Color}
+	for _, diag := range diags {
+		r.diagnostic(diag)
+	}
+	_, err := io.WriteString(w, r.out.String())
+	return err
+}
+
+type renderer struct {
+	out    strings.Builder
+	source string
+	color  bool
+}
+
+func (r *renderer) paint(style, s string) string {
+	if !r.color {
+		return s
+	}
+	return style + s + ansiReset
+}
+
+func (r *renderer) diagnostic(diag *parser.Diagnostic) {
+	start := diag.Span.Start
+	line, lineStart := r.lineAt(start.Offset)
+	gutter := strings.Repeat(" ", len(fmt.Sprint(start.Line)))
+
+	fmt.Fprintf(&r.out, "%s%s\n",
+		r.paint(severityStyle(diag.Severity), fmt.Sprintf("%s[%s]", diag.Severity, diag.Code)),
+		r.paint(ansiBold, ": "+diag.Message))
+	fmt.Fprintf(&r.out, "%s%s %s\n", gutter, r.paint(ansiBlue, "-->"), start)
+
+	if !start.IsValid() || start.Offset > len(r.source) {
+		r.hint(gutter, diag.Hint)
+		return
+	}
+
+	fmt.Fprintf(&r.out, "%s %s\n", gutter, r.paint(ansiBlue, "|"))
+	fmt.Fprintf(&r.out, "%s %s %s\n", r.paint(ansiBlue, fmt.Sprint(start.Line)), r.paint(ansiBlue, "|"), line)
+
+	var padding strings.Builder
+	for _, ch := range r.source[lineStart:start.Offset] {
+		if ch == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+
+	end := min(max(diag.Span.End.Offset, start.Offset), lineStart+len(line))
+	width := max(utf8.RuneCountInString(r.source[start.Offset:end]), 1)
+	fmt.Fprintf(&r.out, "%s %s %s%s\n", gutter, r.paint(ansiBlue, "|"),
+		padding.String(), r.paint(severityStyle(diag.Severity), strings.Repeat("^", width)))
+
+	r.hint(gutter, diag.Hint)
+}
+
+func (r *renderer) hint(gutter, hint string) {
+	if hint == "" {
+		return
+	}
+	fmt.Fprintf(&r.out, "%s %s %s\n", gutter, r.paint(ansiBlue, "="), r.paint(ansiBold, "help:")+" "+hint)
+}
+
+// lineAt returns the line containing offset, without its line terminator,
+// and the offset at which that line starts. A byte order mark at the start
+// of the source is not part of the first line.
+func (r *renderer) lineAt(offset int) (string, int) {
+	offset = min(max(offset, 0), len(r.source))
+	start := strings.LastIndexByte(r.source[:offset], '\n') + 1
+	if start == 0 && strings.HasPrefix(r.source, byteOrderMark) && offset >= len(byteOrderMark) {
+		start = len(byteOrderMark)
+	}
+	end := strings.IndexByte(r.source[start:], '\n')
+	if end < 0 {
+		end = len(r.source)
+	} else {
+		end += start
+	}
+	return strings.TrimSuffix(r.source[start:end], "\r"), start
+}
+
+func severityStyle(severity parser.Severity) string {
+	switch severity {
+	case parser.SeverityError:
+		return ansiRed
+	default:
+		return ansiCyan
+	}
+}
+
+type jsonPosition struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type jsonDiagnostic struct {
+	Severity string            `json:"severity"`
+	Code     parser.ErrorCode  `json:"code"`
+	Message  string            `json:"message"`
+	File     string            `json:"file,omitempty"`
+	Start    jsonPosition      `json:"start"`
+	End      jsonPosition      `json:"end"`
+	Expected []token.TokenType `json:"expected,omitempty"`
+	Actual   token.TokenType   `json:"actual,omitempty"`
+	Hint     string            `json:"hint,omitempty"`
+}
+
+func renderJSON(w io.Writer, diags []*parser.Diagnostic) error {
+	out := make([]jsonDiagnostic, 0, len(diags))
+	for _, diag := range diags {
+		out = append(out, jsonDiagnostic{
+			Severity: diag.Severity.String(),
+			Code:     diag.Code,
+			Message:  diag.Message,
+			File:     diag.Span.Start.Filename,
+			Start:    toJSONPosition(diag.Span.Start),
+			End:      toJSONPosition(diag.Span.End),
+			Expected: diag.Expected,
+			Actual:   diag.Actual,
+			Hint:     diag.Hint,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func toJSONPosition(pos token.Position) jsonPosition {
+	return jsonPosition{Offset: pos.Offset, Line: pos.Line, Column: pos.Column}
+}
@@ -0,0 +1,95 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sayandipdutta/monkey/lexer"
+	"github.com/sayandipdutta/monkey/parser"
+	"github.com/sayandipdutta/monkey/report"
+)
+
+func parse(filename, input string) []*parser.Diagnostic {
+	p := parser.New(lexer.NewFile(filename, input))
+	p.ParseProgram()
+	return p.Diagnostics
+}
+
+func TestRenderPlain(t *testing.T) {
+	input := "let a = 1;\nlet x 5;"
+	diags := parse("main.mk", input)
+
+	var out bytes.Buffer
+	if err := report.Render(&out, input, diags[:1], report.Plain); err != nil {
+		t.Fatalf("Render returned error: %s", err)
+	}
+
+	expected := "error[E0001]: expected next token to be =, got INT\n" +
+		" --> main.mk:2:7\n" +
+		"  |\n" +
+		"2 | let x 5;\n" +
+		"  |       ^\n" +
+		"  = help: did you forget `=` after `let x`?\n"
+	if out.String() != expected {
+		t.Fatalf("wrong output. expected=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
+
+func TestRenderSkipsByteOrderMark(t *testing.T) {
+	input := "\uFEFFlet x 5;"
+	diags := parse("", input)
+
+	var out bytes.Buffer
+	if err := report.Render(&out, input, diags[:1], report.Plain); err != nil {
+		t.Fatalf("Render returned error: %s", err)
+	}
+
+	expected := "error[E0001]: expected next token to be =, got INT\n" +
+		" --> 1:7\n" +
+		"  |\n" +
+		"1 | let x 5;\n" +
+		"  |       ^\n" +
+		"  = help: did you forget `=` after `let x`?\n"
+	if out.String() != expected {
+		t.Fatalf("wrong output. expected=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
+
+func TestRenderColor(t *testing.T) {
+	input := "let x 5;"
+	diags := parse("", input)
+
+	var out bytes.Buffer
+	if err := report.Render(&out, input, diags[:1], report.Color); err != nil {
+		t.Fatalf("Render returned error: %s", err)
+	}
+
+	if !bytes.Contains(out.Bytes(), []byte("\x1b[1;31merror[E0001]\x1b[0m")) {
+		t.Fatalf("expected colored severity, got=%q", out.String())
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	input := "let x 5;"
+	diags := parse("main.mk", input)
+
+	var out bytes.Buffer
+	if err := report.Render(&out, input, diags[:1], report.JSON); err != nil {
+		t.Fatalf("Render returned error: %s", err)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %s", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 diagnostic, got=%d", len(got))
+	}
+	if got[0]["code"] != "E0001" || got[0]["file"] != "main.mk" {
+		t.Fatalf("wrong diagnostic. got=%v", got[0])
+	}
+	if got[0]["hint"] != "did you forget `=` after `let x`?" {
+		t.Fatalf("wrong hint. got=%v", got[0]["hint"])
+	}
+}
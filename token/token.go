@@ -1,24 +1,67 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position
+}
+
+// Position describes a location in the source. Offset is a zero-based byte
+// offset, Line and Column are one-based; Column counts characters, not
+// bytes. A Position is valid if Line > 0.
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+func (pos Position) IsValid() bool { return pos.Line > 0 }
+
+// String returns the position in one of the forms
+//
+//	file:line:column
+//	line:column
+//	-
+func (pos Position) String() string {
+	if !pos.IsValid() {
+		if pos.Filename != "" {
+			return pos.Filename
+		}
+		return "-"
+	}
+	s := fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+	if pos.Filename != "" {
+		s = pos.Filename + ":" + s
+	}
+	return s
 }
 
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	COMMENT = "COMMENT"
 
-	IDENT = "IDENT"
-	INT   = "INT"
+	IDENT  = "IDENT"
+	INT    = "INT"
+	FLOAT  = "FLOAT"
+	STRING = "STRING"
 
 	ASSIGN    = "="
 	PLUS      = "+"
 	MINUS     = "-"
 	ASTERISK  = "*"
+	POWER     = "**"
 	SLASH     = "/"
+	PERCENT   = "%"
+	AMPERSAND = "&"
+	PIPE      = "|"
+	CARET     = "^"
+	TILDE     = "~"
 	BANG      = "!"
 	GT        = ">"
 	LT        = "<"
@@ -32,14 +75,19 @@ const (
 	IMINUS    = "-="
 	IASTERISK = "*="
 	ISLASH    = "/="
+	AND       = "&&"
+	OR        = "||"
 
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
 
-	LPAREN = ")"
-	RPAREN = "("
-	LBRACE = "}"
-	RBRACE = "{"
+	LPAREN   = "("
+	RPAREN   = ")"
+	LBRACE   = "{"
+	RBRACE   = "}"
+	LBRACKET = "["
+	RBRACKET = "]"
 
 	FUNCTION = "FUNCTION"
 	LET      = "LET"